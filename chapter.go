@@ -0,0 +1,70 @@
+package toc
+
+import "github.com/yuin/goldmark/ast"
+
+// addChapterTOCs adds a table of contents after each chapter heading
+// in the document.
+//
+// items maps items of the document's table of contents
+// to their headings.
+// If the table of contents is numbered,
+// chapter tables of contents use the same numbers.
+func (t *Transformer) addChapterTOCs(doc *ast.Document, src []byte, items map[*Item]*ast.Heading) {
+	numbers := make(map[*ast.Heading][]int, len(items))
+	for item, h := range items {
+		numbers[h] = item.Number
+	}
+
+	var chapters []*ast.Heading
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		if h, ok := n.(*ast.Heading); ok {
+			if h.Level == t.ChapterLevel {
+				chapters = append(chapters, h)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	opts := append(t.inspectOptions(),
+		MinDepth(0),
+		MaxDepth(0),
+		RelativeMinDepth(0),
+		RelativeMaxDepth(t.ChapterMaxDepth),
+		Normalize(true),
+		OmitLeadingTitle(false),
+		// Chapters are numbered like the rest of the document,
+		// not from 1.
+		Numbered(false),
+		onItemOption(func(item *Item, h *ast.Heading) {
+			item.Number = numbers[h]
+		}),
+	)
+
+	renderer := t.listRenderer()
+	for _, chapter := range chapters {
+		toc, err := Inspect(doc, src, append(opts, sectionOption{chapter})...)
+		if err != nil || countTitled(toc.Items) < max(t.ChapterMinItems, 1) {
+			continue
+		}
+
+		nodes := []ast.Node{renderer.Render(toc)}
+		if title := t.ChapterTitle; len(title) > 0 {
+			para := ast.NewParagraph()
+			para.AppendChild(para, ast.NewString([]byte(title)))
+			nodes = append([]ast.Node{para}, nodes...)
+		}
+		if t.Wrap {
+			node := NewNode(toc)
+			for _, n := range nodes {
+				node.AppendChild(node, n)
+			}
+			nodes = []ast.Node{node}
+		}
+		insertAfter(chapter, nodes)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+)
+
+// githubIDs generates heading IDs the same way as GitHub
+// does for anchors in rendered Markdown files.
+//
+// Titles are lowercased, spaces become '-',
+// and punctuation other than '-' and '_' is dropped.
+// Duplicate IDs get a "-1", "-2", etc. suffix.
+type githubIDs struct {
+	seen map[string]int // ID => number of times it was generated
+}
+
+var _ parser.IDs = (*githubIDs)(nil)
+
+func newGitHubIDs() *githubIDs {
+	return &githubIDs{seen: make(map[string]int)}
+}
+
+// Generate generates an ID for a heading with the given text content.
+func (ids *githubIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	var buf bytes.Buffer
+	for _, r := range string(bytes.ToLower(value)) {
+		switch {
+		case r == ' ':
+			buf.WriteByte('-')
+		case r == '-', unicode.IsLetter(r), unicode.IsNumber(r), unicode.IsMark(r),
+			unicode.Is(unicode.Pc, r): // connector punctuation, e.g. '_'
+			buf.WriteRune(r)
+		}
+	}
+
+	base := buf.String()
+	id := base
+	for {
+		if _, ok := ids.seen[id]; !ok {
+			break
+		}
+		ids.seen[base]++
+		id = base + "-" + strconv.Itoa(ids.seen[base])
+	}
+	ids.seen[id] = 0
+	return []byte(id)
+}
+
+// Put records an ID that is already in use.
+func (ids *githubIDs) Put(value []byte) {
+	ids.seen[string(value)] = 0
+}
+
+// setHeadingIDs assigns IDs to all headings in the document
+// based on their text content, in document order.
+func setHeadingIDs(doc ast.Node, src []byte, ids parser.IDs) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if h, ok := n.(*ast.Heading); ok {
+			id := ids.Generate(headingText(h, src), ast.KindHeading)
+			h.SetAttributeString("id", id)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// headingText returns the text content of a heading
+// as it appears in the rendered HTML.
+// Markup, raw HTML tags, and images are left out.
+func headingText(h *ast.Heading, src []byte) []byte {
+	var buf bytes.Buffer
+	_ = ast.Walk(h, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			buf.Write(n.Label(src))
+		case *ast.Text:
+			buf.Write(n.Segment.Value(src))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(n.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return buf.Bytes()
+}
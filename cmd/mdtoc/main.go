@@ -0,0 +1,285 @@
+// mdtoc inserts or refreshes a table of contents inside Markdown files.
+//
+// The table of contents is placed between the following markers
+// in each file:
+//
+//	<!-- toc -->
+//	<!-- tocstop -->
+//
+// If the file only contains the opening marker,
+// the closing marker is added after the table of contents.
+//
+// Links in the table of contents use the same heading anchors
+// as GitHub's rendering of Markdown files.
+//
+// # Usage
+//
+//	mdtoc [options] [FILE ...]
+//
+// Files are rewritten in-place.
+// If no files are given, mdtoc reads from stdin and writes to stdout.
+//
+// Use -check in CI to verify that the tables of contents are up-to-date.
+// In this mode, mdtoc does not modify files,
+// and exits with a non-zero status if any of them are stale.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"go.abhg.dev/goldmark/toc"
+)
+
+const (
+	_startMarker = "<!-- toc -->"
+	_stopMarker  = "<!-- tocstop -->"
+)
+
+func main() {
+	cmd := mainCmd{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+	os.Exit(cmd.Run(os.Args[1:]))
+}
+
+type mainCmd struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+type params struct {
+	Title      string
+	TitleDepth int
+	MinDepth   int
+	MaxDepth   int
+	Compact    bool
+	Ordered    bool
+	Check      bool
+
+	Files []string
+}
+
+func (cmd *mainCmd) parseParams(args []string) (*params, error) {
+	flag := flag.NewFlagSet("mdtoc", flag.ContinueOnError)
+	flag.SetOutput(cmd.Stderr)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.Output(), "usage: mdtoc [options] [FILE ...]")
+		fmt.Fprintln(flag.Output())
+		fmt.Fprintf(flag.Output(), "Inserts or refreshes the table of contents between %v and %v in FILEs.\n", _startMarker, _stopMarker)
+		fmt.Fprintln(flag.Output(), "Reads from stdin and writes to stdout if no FILEs are given.")
+		fmt.Fprintln(flag.Output())
+		fmt.Fprintln(flag.Output(), "options:")
+		flag.PrintDefaults()
+	}
+
+	var p params
+	flag.StringVar(&p.Title, "title", "", "title of the table of contents `heading`, if any")
+	flag.IntVar(&p.TitleDepth, "title-depth", 2, "heading `level` of the title")
+	flag.IntVar(&p.MinDepth, "min-depth", 0, "ignore headings shallower than this `level`")
+	flag.IntVar(&p.MaxDepth, "max-depth", 0, "ignore headings deeper than this `level`")
+	flag.BoolVar(&p.Compact, "compact", false, "remove empty items from the table of contents")
+	flag.BoolVar(&p.Ordered, "ordered", false, "render an ordered list")
+	flag.BoolVar(&p.Check, "check", false, "don't modify files; exit with a non-zero status if any are out of date")
+
+	if err := flag.Parse(args); err != nil {
+		return nil, err
+	}
+	p.Files = flag.Args()
+
+	if p.TitleDepth < 1 || p.TitleDepth > 6 {
+		return nil, fmt.Errorf("-title-depth must be in [1, 6], got %d", p.TitleDepth)
+	}
+
+	return &p, nil
+}
+
+func (cmd *mainCmd) Run(args []string) (exitCode int) {
+	p, err := cmd.parseParams(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintln(cmd.Stderr, "mdtoc:", err)
+		return 2
+	}
+
+	if len(p.Files) == 0 {
+		if err := cmd.runStdin(p); err != nil {
+			fmt.Fprintln(cmd.Stderr, "mdtoc:", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, path := range p.Files {
+		if err := cmd.runFile(p, path); err != nil {
+			fmt.Fprintf(cmd.Stderr, "mdtoc: %v: %v\n", path, err)
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+var errStale = errors.New("table of contents is out of date")
+
+func (cmd *mainCmd) runStdin(p *params) error {
+	src, err := io.ReadAll(cmd.Stdin)
+	if err != nil {
+		return err
+	}
+
+	got, err := refresh(p, src)
+	if err != nil {
+		return err
+	}
+
+	if p.Check {
+		if !bytes.Equal(src, got) {
+			return errStale
+		}
+		return nil
+	}
+
+	_, err = cmd.Stdout.Write(got)
+	return err
+}
+
+func (cmd *mainCmd) runFile(p *params, path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	got, err := refresh(p, src)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(src, got) {
+		return nil
+	}
+	if p.Check {
+		return errStale
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, got, info.Mode())
+}
+
+// refresh returns a copy of src with the table of contents
+// between the markers replaced with an up-to-date one.
+func refresh(p *params, src []byte) ([]byte, error) {
+	// Heading IDs depend on every heading in the file,
+	// including the title of the table of contents.
+	// The first pass may add or change the title,
+	// so the second pass builds links from IDs
+	// in the file as it will be written.
+	out, err := refreshOnce(p, src)
+	if err != nil {
+		return nil, err
+	}
+	return refreshOnce(p, out)
+}
+
+func refreshOnce(p *params, src []byte) ([]byte, error) {
+	doc := goldmark.New().Parser().Parse(text.NewReader(src))
+	setHeadingIDs(doc, src, newGitHubIDs())
+
+	start, stop := findMarkers(doc, src)
+	if start == nil {
+		return nil, fmt.Errorf("%v marker not found", _startMarker)
+	}
+
+	// The table of contents is placed between
+	// the end of the start marker and the beginning of the stop marker.
+	regionStart := lastLine(start).Stop
+	regionStop := regionStart
+	if stop != nil {
+		regionStop = stop.Lines().At(0).Start
+	}
+
+	tree, err := toc.Inspect(doc, src,
+		toc.MinDepth(p.MinDepth),
+		toc.MaxDepth(p.MaxDepth),
+		toc.Compact(p.Compact),
+		// Don't include headings from the old table of contents.
+		toc.Filter(func(h *ast.Heading, _ []byte) bool {
+			pos := h.Pos()
+			return pos < regionStart || pos >= regionStop
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.Write(src[:regionStart])
+	if regionStart > 0 && src[regionStart-1] != '\n' {
+		out.WriteByte('\n')
+	}
+	out.WriteByte('\n')
+	if len(p.Title) > 0 {
+		out.WriteString(strings.Repeat("#", p.TitleDepth))
+		out.WriteByte(' ')
+		out.WriteString(p.Title)
+		out.WriteString("\n\n")
+	}
+	if len(tree.Items) > 0 {
+		renderer := toc.MarkdownRenderer{Marker: '-'}
+		if p.Ordered {
+			renderer.Marker = '.'
+		}
+		if err := renderer.Render(&out, tree); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+	}
+	if stop == nil {
+		out.WriteString(_stopMarker)
+		out.WriteByte('\n')
+	}
+	out.Write(src[regionStop:])
+	return out.Bytes(), nil
+}
+
+// findMarkers finds the HTML blocks holding the start and stop markers
+// at the top level of the document.
+//
+// stop is nil if the document does not have a stop marker
+// after the start marker.
+func findMarkers(doc ast.Node, src []byte) (start, stop ast.Node) {
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if _, ok := n.(*ast.HTMLBlock); !ok {
+			continue
+		}
+
+		value := string(bytes.TrimSpace(n.Lines().Value(src)))
+		switch {
+		case start == nil && value == _startMarker:
+			start = n
+		case start != nil && value == _stopMarker:
+			return start, n
+		}
+	}
+	return start, nil
+}
+
+func lastLine(n ast.Node) text.Segment {
+	lines := n.Lines()
+	return lines.At(lines.Len() - 1)
+}
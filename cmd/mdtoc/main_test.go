@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		args []string
+		give string
+		want string
+	}{
+		{
+			desc: "empty markers",
+			give: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"",
+				"## Installation",
+				"",
+				"## Usage",
+				"",
+				"### Flags",
+			),
+			want: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"",
+				"- [Project](#project)",
+				"  - [Installation](#installation)",
+				"  - [Usage](#usage)",
+				"    - [Flags](#flags)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## Installation",
+				"",
+				"## Usage",
+				"",
+				"### Flags",
+			),
+		},
+		{
+			desc: "title matches heading",
+			args: []string{"-title", "Contents"},
+			give: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"",
+				"## Contents",
+			),
+			want: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"",
+				"## Contents",
+				"",
+				"- [Project](#project)",
+				"  - [Contents](#contents-1)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## Contents",
+			),
+		},
+		{
+			desc: "github ids",
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"",
+				"# Über uns",
+				"# snake_case name",
+				"# The `Render()` method",
+				"# [Links](https://example.com) & *emphasis*",
+				"# Über uns",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [Über uns](#über-uns)",
+				"- [snake\\_case name](#snake_case-name)",
+				"- [The Render() method](#the-render-method)",
+				"- [Links \\& emphasis](#links--emphasis)",
+				"- [Über uns](#über-uns-1)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"# Über uns",
+				"# snake_case name",
+				"# The `Render()` method",
+				"# [Links](https://example.com) & *emphasis*",
+				"# Über uns",
+			),
+		},
+		{
+			desc: "stale",
+			args: []string{"-min-depth", "2"},
+			give: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"",
+				"- [Old](#old)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## New",
+			),
+			want: joinLines(
+				"# Project",
+				"",
+				"<!-- toc -->",
+				"",
+				"- - [New](#new)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"## New",
+			),
+		},
+		{
+			desc: "no stop marker",
+			give: joinLines(
+				"<!-- toc -->",
+				"",
+				"# Foo",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"",
+				"<!-- tocstop -->",
+				"",
+				"# Foo",
+			),
+		},
+		{
+			desc: "no trailing newline",
+			give: "# Foo\n\n<!-- toc -->",
+			want: joinLines(
+				"# Foo",
+				"",
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"",
+				"<!-- tocstop -->",
+			),
+		},
+		{
+			desc: "title",
+			args: []string{"-title", "Contents", "-title-depth", "3"},
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"# Foo",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"### Contents",
+				"",
+				"- [Foo](#foo)",
+				"",
+				"<!-- tocstop -->",
+				"# Foo",
+			),
+		},
+		{
+			desc: "ordered",
+			args: []string{"-ordered"},
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"# Foo",
+				"## Bar",
+				"## Baz",
+				"# Qux",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"1. [Foo](#foo)",
+				"   1. [Bar](#bar)",
+				"   2. [Baz](#baz)",
+				"2. [Qux](#qux)",
+				"",
+				"<!-- tocstop -->",
+				"# Foo",
+				"## Bar",
+				"## Baz",
+				"# Qux",
+			),
+		},
+		{
+			desc: "empty items",
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"# Foo",
+				"### Bar",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"  - - [Bar](#bar)",
+				"",
+				"<!-- tocstop -->",
+				"# Foo",
+				"### Bar",
+			),
+		},
+		{
+			desc: "compact",
+			args: []string{"-compact", "-max-depth", "3"},
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"# Foo",
+				"### Bar",
+				"#### Baz",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"  - [Bar](#bar)",
+				"",
+				"<!-- tocstop -->",
+				"# Foo",
+				"### Bar",
+				"#### Baz",
+			),
+		},
+		{
+			desc: "escaped title",
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"# The `Render` *method* [v2]",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"- [The Render method \\[v2\\]](#the-render-method-v2)",
+				"",
+				"<!-- tocstop -->",
+				"# The `Render` *method* [v2]",
+			),
+		},
+		{
+			desc: "markers in code block",
+			give: joinLines(
+				"```",
+				"<!-- toc -->",
+				"```",
+				"",
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"# Foo",
+			),
+			want: joinLines(
+				"```",
+				"<!-- toc -->",
+				"```",
+				"",
+				"<!-- toc -->",
+				"",
+				"- [Foo](#foo)",
+				"",
+				"<!-- tocstop -->",
+				"# Foo",
+			),
+		},
+		{
+			desc: "no headings",
+			give: joinLines(
+				"<!-- toc -->",
+				"<!-- tocstop -->",
+				"Nothing here.",
+			),
+			want: joinLines(
+				"<!-- toc -->",
+				"",
+				"<!-- tocstop -->",
+				"Nothing here.",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			cmd := mainCmd{
+				Stdin:  strings.NewReader(tt.give),
+				Stdout: &stdout,
+				Stderr: &stderr,
+			}
+			require.Zero(t, cmd.Run(tt.args), "stderr:\n%s", stderr.String())
+			assert.Equal(t, tt.want, stdout.String())
+
+			// Running it again should not change anything.
+			stdout.Reset()
+			cmd.Stdin = strings.NewReader(tt.want)
+			require.Zero(t, cmd.Run(append([]string{"-check"}, tt.args...)),
+				"stderr:\n%s", stderr.String())
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "stale.md")
+	fresh := filepath.Join(dir, "fresh.md")
+	freshContents := joinLines(
+		"<!-- toc -->",
+		"",
+		"- [Foo](#foo)",
+		"",
+		"<!-- tocstop -->",
+		"",
+		"# Foo",
+	)
+	staleContents := joinLines(
+		"<!-- toc -->",
+		"<!-- tocstop -->",
+		"",
+		"# Foo",
+	)
+	require.NoError(t, os.WriteFile(stale, []byte(staleContents), 0o644))
+	require.NoError(t, os.WriteFile(fresh, []byte(freshContents), 0o644))
+
+	t.Run("check", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		cmd := mainCmd{Stdout: &stdout, Stderr: &stderr}
+		assert.Equal(t, 1, cmd.Run([]string{"-check", stale, fresh}))
+		assert.Equal(t, "mdtoc: "+stale+": table of contents is out of date\n", stderr.String())
+
+		got, err := os.ReadFile(stale)
+		require.NoError(t, err)
+		assert.Equal(t, staleContents, string(got), "file must not be modified")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		cmd := mainCmd{Stdout: &stdout, Stderr: &stderr}
+		require.Zero(t, cmd.Run([]string{stale, fresh}), "stderr:\n%s", stderr.String())
+		assert.Empty(t, stdout.String())
+
+		got, err := os.ReadFile(stale)
+		require.NoError(t, err)
+		assert.Equal(t, joinLines(
+			"<!-- toc -->",
+			"",
+			"- [Foo](#foo)",
+			"",
+			"<!-- tocstop -->",
+			"",
+			"# Foo",
+		), string(got))
+
+		got, err = os.ReadFile(fresh)
+		require.NoError(t, err)
+		assert.Equal(t, freshContents, string(got))
+	})
+}
+
+func TestFiles_titleMatchesHeading(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "README.md")
+	require.NoError(t, os.WriteFile(path, []byte(joinLines(
+		"<!-- toc -->",
+		"<!-- tocstop -->",
+		"",
+		"## Contents",
+	)), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	cmd := mainCmd{Stdout: &stdout, Stderr: &stderr}
+	require.Zero(t, cmd.Run([]string{"-title", "Contents", path}), "stderr:\n%s", stderr.String())
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "- [Contents](#contents-1)")
+
+	require.Zero(t, cmd.Run([]string{"-check", "-title", "Contents", path}),
+		"table of contents must be up-to-date after a run; stderr:\n%s", stderr.String())
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	noMarker := filepath.Join(dir, "no-marker.md")
+	require.NoError(t, os.WriteFile(noMarker, []byte("# Foo\n"), 0o644))
+
+	tests := []struct {
+		desc     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantErr  string
+	}{
+		{
+			desc:     "no marker",
+			args:     []string{noMarker},
+			wantCode: 1,
+			wantErr:  "mdtoc: " + noMarker + ": <!-- toc --> marker not found\n",
+		},
+		{
+			desc:     "no marker/stdin",
+			stdin:    "# Foo\n",
+			wantCode: 1,
+			wantErr:  "mdtoc: <!-- toc --> marker not found\n",
+		},
+		{
+			desc:     "stale/stdin",
+			args:     []string{"-check"},
+			stdin:    "<!-- toc -->\n# Foo\n",
+			wantCode: 1,
+			wantErr:  "mdtoc: table of contents is out of date\n",
+		},
+		{
+			desc:     "missing file",
+			args:     []string{filepath.Join(dir, "missing.md")},
+			wantCode: 1,
+			wantErr:  "no such file or directory",
+		},
+		{
+			desc:     "bad title depth",
+			args:     []string{"-title-depth", "7"},
+			wantCode: 2,
+			wantErr:  "-title-depth must be in [1, 6], got 7",
+		},
+		{
+			desc:     "unknown flag",
+			args:     []string{"-unknown"},
+			wantCode: 2,
+			wantErr:  "flag provided but not defined: -unknown",
+		},
+		{
+			desc:    "help",
+			args:    []string{"-h"},
+			wantErr: "usage: mdtoc [options] [FILE ...]",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			cmd := mainCmd{
+				Stdin:  strings.NewReader(tt.stdin),
+				Stdout: &stdout,
+				Stderr: &stderr,
+			}
+			assert.Equal(t, tt.wantCode, cmd.Run(tt.args))
+			assert.Contains(t, stderr.String(), tt.wantErr)
+		})
+	}
+}
+
+func joinLines(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,31 @@
+package toc
+
+import "github.com/yuin/goldmark/parser"
+
+// ContextKey is the key under which the Transformer stores
+// the table of contents of a document in its parser.Context.
+//
+// Use FromContext to retrieve it.
+var ContextKey = parser.NewContextKey()
+
+// FromContext returns the table of contents that the Transformer
+// built for the document parsed with the given parser.Context.
+//
+// This may be used to render the table of contents separately
+// from the document, e.g. in a sidebar.
+//
+//	ctx := parser.NewContext()
+//	if err := markdown.Convert(src, &buf, parser.WithContext(ctx)); err != nil {
+//		// ...
+//	}
+//	tree := toc.FromContext(ctx)
+//
+// Set the Transformer's Position to PositionNone
+// to build the table of contents without adding it to the document.
+//
+// Returns nil if the Transformer did not run for this document,
+// or if the table of contents was disabled for it.
+func FromContext(ctx parser.Context) *TOC {
+	toc, _ := ctx.Get(ContextKey).(*TOC)
+	return toc
+}
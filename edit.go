@@ -0,0 +1,252 @@
+package toc
+
+import "slices"
+
+// The methods in this file return modified copies of a table of contents
+// and leave the original untouched,
+// so a table of contents may be shared between callers.
+// They treat a nil TOC as an empty one.
+//
+// Copies share RichTitle and Heading with the original
+// because those refer to the parsed document.
+
+// Clone returns a deep copy of the table of contents.
+// Clone returns nil if the table of contents is nil.
+func (t *TOC) Clone() *TOC {
+	if t == nil {
+		return nil
+	}
+	return &TOC{Items: cloneItems(t.Items)}
+}
+
+// Clone returns a deep copy of the item and its descendants.
+// Clone returns nil if the item is nil.
+func (i *Item) Clone() *Item {
+	if i == nil {
+		return nil
+	}
+
+	item := *i
+	item.Title = slices.Clone(i.Title)
+	item.ID = slices.Clone(i.ID)
+	item.URL = slices.Clone(i.URL)
+	item.Number = slices.Clone(i.Number)
+	item.Items = cloneItems(i.Items)
+	return &item
+}
+
+func cloneItems(items Items) Items {
+	if items == nil {
+		return nil
+	}
+
+	cloned := make(Items, len(items))
+	for idx, item := range items {
+		cloned[idx] = item.Clone()
+	}
+	return cloned
+}
+
+// items returns a deep copy of the items in the table of contents.
+func (t *TOC) items() Items {
+	if t == nil {
+		return nil
+	}
+	return cloneItems(t.Items)
+}
+
+// Filter returns a copy of the table of contents
+// without items for which keep returns false.
+// Children of removed items take the place of their parents.
+//
+// For example, given the following,
+// removing Bar results in Foo > Baz.
+//
+//	Foo
+//	  Bar
+//	    Baz
+//
+// Use Prune to remove children alongside their parents.
+func (t *TOC) Filter(keep func(*Item) bool) *TOC {
+	return &TOC{Items: filterItems(t.items(), keep, true)}
+}
+
+// Prune returns a copy of the table of contents
+// without items for which keep returns false,
+// and without their descendants.
+//
+// For example, given the following,
+// removing Bar results in just Foo.
+//
+//	Foo
+//	  Bar
+//	    Baz
+//
+// Use Filter to keep the descendants of removed items.
+func (t *TOC) Prune(keep func(*Item) bool) *TOC {
+	return &TOC{Items: filterItems(t.items(), keep, false)}
+}
+
+// Compact returns a copy of the table of contents
+// without items that don't have titles.
+// Children of removed items take the place of their parents.
+//
+// See the Compact option for more information.
+func (t *TOC) Compact() *TOC {
+	return t.Filter(func(item *Item) bool {
+		return len(item.Title) > 0
+	})
+}
+
+// filterItems removes items for which keep returns false
+// from the given items, modifying them in place.
+// If promote is true, children of removed items are kept in their place.
+func filterItems(items Items, keep func(*Item) bool, promote bool) Items {
+	var kept Items
+	for _, item := range items {
+		if keep(item) {
+			item.Items = filterItems(item.Items, keep, promote)
+			kept = append(kept, item)
+		} else if promote {
+			kept = append(kept, filterItems(item.Items, keep, promote)...)
+		}
+	}
+	return kept
+}
+
+// Map returns a copy of the table of contents
+// with fn applied to each item in depth-first order.
+// fn receives the copy of the item, and may change it freely,
+// e.g. to change its title or ID.
+//
+//	upper := tree.Map(func(item *toc.Item) {
+//		item.Title = bytes.ToUpper(item.Title)
+//	})
+func (t *TOC) Map(fn func(*Item)) *TOC {
+	items := t.items()
+	items.Walk(func(item *Item, _ int) WalkStatus {
+		fn(item)
+		return WalkContinue
+	})
+	return &TOC{Items: items}
+}
+
+// Truncate returns a copy of the table of contents
+// without items deeper than the given depth.
+// Top-level items are at depth 1.
+//
+// For example, Truncate(2) keeps top-level items and their children.
+// Truncate returns an unchanged copy if depth is 0 or less.
+func (t *TOC) Truncate(depth int) *TOC {
+	items := t.items()
+	if depth > 0 {
+		items.Walk(func(item *Item, d int) WalkStatus {
+			if d >= depth {
+				item.Items = nil
+				return WalkSkipChildren
+			}
+			return WalkContinue
+		})
+	}
+	return &TOC{Items: items}
+}
+
+// Collapse returns a copy of the table of contents
+// where chains of items without titles are collapsed
+// into the first item with a title under them.
+// This removes the empty items left by gaps between heading levels
+// while keeping all items with titles.
+//
+// Only items without titles that have a single child are removed.
+// Unlike Compact, items without titles that group several children
+// are kept.
+//
+// For example, given the following,
+// where "-" is an item without a title:
+//
+//	Foo
+//	  -
+//	    -
+//	      Bar
+//	Baz
+//	  -
+//	    Qux
+//	    Quux
+//
+// Collapse results in:
+//
+//	Foo
+//	  Bar
+//	Baz
+//	  -
+//	    Qux
+//	    Quux
+func (t *TOC) Collapse() *TOC {
+	items := t.items()
+	collapseItems(items)
+	return &TOC{Items: items}
+}
+
+func collapseItems(items Items) {
+	for i, item := range items {
+		for len(item.Title) == 0 && len(item.Items) == 1 {
+			item = item.Items[0]
+		}
+		items[i] = item
+		collapseItems(item.Items)
+	}
+}
+
+// Sort returns a copy of the table of contents
+// with the children of each item sorted with the given comparison function.
+// cmp must follow the same rules as the function passed to slices.SortFunc.
+// The sort is stable.
+//
+//	byTitle := tree.Sort(func(a, b *toc.Item) int {
+//		return bytes.Compare(a.Title, b.Title)
+//	})
+func (t *TOC) Sort(cmp func(a, b *Item) int) *TOC {
+	items := t.items()
+	sortItems(items, cmp)
+	return &TOC{Items: items}
+}
+
+func sortItems(items Items, cmp func(a, b *Item) int) {
+	slices.SortStableFunc(items, cmp)
+	for _, item := range items {
+		sortItems(item.Items, cmp)
+	}
+}
+
+// Under returns a copy of the table of contents
+// with all its items placed under a copy of the given parent item.
+// The items are added after the parent's own children.
+//
+// Use this with Merge to combine tables of contents
+// of several documents.
+//
+//	site := toc.Merge(
+//		guide.Under(&toc.Item{Title: []byte("Guide"), ID: []byte("guide")}),
+//		api.Under(&toc.Item{Title: []byte("API"), ID: []byte("api")}),
+//	)
+func (t *TOC) Under(parent *Item) *TOC {
+	item := parent.Clone()
+	if item == nil {
+		item = new(Item)
+	}
+	item.Items = append(item.Items, t.items()...)
+	return &TOC{Items: Items{item}}
+}
+
+// Merge returns a table of contents with copies of
+// the items of the given tables of contents, in order.
+// Nil tables of contents are skipped.
+//
+// See Under for an example.
+func Merge(tocs ...*TOC) *TOC {
+	var items Items
+	for _, t := range tocs {
+		items = append(items, t.items()...)
+	}
+	return &TOC{Items: items}
+}
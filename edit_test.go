@@ -0,0 +1,290 @@
+package toc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOCEdit(t *testing.T) {
+	t.Parallel()
+
+	hasTitle := func(titles ...string) func(*Item) bool {
+		return func(it *Item) bool {
+			for _, title := range titles {
+				if string(it.Title) == title {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	// All cases operate on testTree:
+	//
+	//	Foo
+	//	  Bar
+	//	    Baz
+	//	  Qux
+	//	Quux
+	tests := []struct {
+		desc string
+		edit func(*TOC) *TOC
+		want Items
+	}{
+		{
+			desc: "clone",
+			edit: (*TOC).Clone,
+			want: testTree().Items,
+		},
+		{
+			desc: "filter",
+			edit: func(t *TOC) *TOC {
+				return t.Filter(func(it *Item) bool {
+					return !hasTitle("Foo", "Bar")(it)
+				})
+			},
+			want: Items{
+				item("Baz", "baz"),
+				item("Qux", "qux"),
+				item("Quux", "quux"),
+			},
+		},
+		{
+			desc: "prune",
+			edit: func(t *TOC) *TOC {
+				return t.Prune(func(it *Item) bool {
+					return !hasTitle("Bar")(it)
+				})
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Qux", "qux")),
+				item("Quux", "quux"),
+			},
+		},
+		{
+			desc: "map",
+			edit: func(t *TOC) *TOC {
+				return t.Map(func(it *Item) {
+					it.Title = bytes.ToUpper(it.Title)
+					it.ID = append([]byte("doc-"), it.ID...)
+				})
+			},
+			want: Items{
+				item("FOO", "doc-foo",
+					item("BAR", "doc-bar",
+						item("BAZ", "doc-baz")),
+					item("QUX", "doc-qux")),
+				item("QUUX", "doc-quux"),
+			},
+		},
+		{
+			desc: "truncate",
+			edit: func(t *TOC) *TOC { return t.Truncate(1) },
+			want: Items{
+				item("Foo", "foo"),
+				item("Quux", "quux"),
+			},
+		},
+		{
+			desc: "truncate/deeper than tree",
+			edit: func(t *TOC) *TOC { return t.Truncate(5) },
+			want: testTree().Items,
+		},
+		{
+			desc: "truncate/no limit",
+			edit: func(t *TOC) *TOC { return t.Truncate(0) },
+			want: testTree().Items,
+		},
+		{
+			desc: "collapse",
+			edit: (*TOC).Collapse,
+			want: testTree().Items,
+		},
+		{
+			desc: "sort",
+			edit: func(t *TOC) *TOC {
+				return t.Sort(func(a, b *Item) int {
+					return -bytes.Compare(a.Title, b.Title)
+				})
+			},
+			want: Items{
+				item("Quux", "quux"),
+				item("Foo", "foo",
+					item("Qux", "qux"),
+					item("Bar", "bar",
+						item("Baz", "baz"))),
+			},
+		},
+		{
+			desc: "under",
+			edit: func(t *TOC) *TOC {
+				return t.Under(item("Guide", "guide",
+					item("Intro", "intro")))
+			},
+			want: Items{
+				item("Guide", "guide",
+					item("Intro", "intro"),
+					item("Foo", "foo",
+						item("Bar", "bar",
+							item("Baz", "baz")),
+						item("Qux", "qux")),
+					item("Quux", "quux")),
+			},
+		},
+		{
+			desc: "merge",
+			edit: func(t *TOC) *TOC {
+				return Merge(t.Truncate(1), nil, &TOC{Items: Items{item("Other", "other")}})
+			},
+			want: Items{
+				item("Foo", "foo"),
+				item("Quux", "quux"),
+				item("Other", "other"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			give := testTree()
+			got := tt.edit(give)
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+			assert.Equal(t, testTree(), give, "input must not be modified")
+
+			// Changes to the result must not affect the input.
+			got.Walk(func(it *Item, _ int) WalkStatus {
+				if len(it.Title) > 0 {
+					it.Title[0] = '!'
+				}
+				it.Items = append(it.Items, item("New", "new"))
+				return WalkSkipChildren
+			})
+			assert.Equal(t, testTree(), give, "input must not share memory with the result")
+		})
+	}
+}
+
+func TestTOCCollapse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give Items
+		want Items
+	}{
+		{
+			desc: "titled chain",
+			give: Items{
+				item("Foo", "foo",
+					item("Bar", "bar",
+						item("Baz", "baz"))),
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar",
+						item("Baz", "baz"))),
+			},
+		},
+		{
+			desc: "single titled child",
+			give: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+			},
+		},
+		{
+			desc: "untitled chain",
+			give: Items{
+				item("Foo", "foo",
+					item("", "",
+						item("", "",
+							item("Bar", "bar")))),
+				item("Baz", "baz",
+					item("", "",
+						item("Qux", "qux"),
+						item("Quux", "quux"))),
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+				item("Baz", "baz",
+					item("", "",
+						item("Qux", "qux"),
+						item("Quux", "quux"))),
+			},
+		},
+		{
+			desc: "untitled",
+			give: Items{
+				item("", "",
+					item("Foo", "foo",
+						item("Bar", "bar"),
+						item("Baz", "baz"))),
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar"),
+					item("Baz", "baz")),
+			},
+		},
+		{
+			desc: "top-level",
+			give: Items{
+				item("Foo", "foo"),
+			},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := (&TOC{Items: tt.give}).Collapse()
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestTOCEdit_url(t *testing.T) {
+	t.Parallel()
+
+	newTree := func() *TOC {
+		foo := item("Foo", "foo", item("Bar", "bar"))
+		foo.URL = []byte("foo.html")
+		foo.Items[0].URL = []byte("foo.html#bar")
+		return &TOC{Items: Items{foo}}
+	}
+
+	give := newTree()
+	got := give.Map(func(it *Item) {
+		it.URL[0] = 'Z'
+	})
+	assert.Equal(t, []byte("Zoo.html"), got.Items[0].URL)
+	assert.Equal(t, []byte("Zoo.html#bar"), got.Items[0].Items[0].URL)
+	assert.Equal(t, newTree(), give, "input must not share memory with the result")
+}
+
+func TestTOCEdit_nil(t *testing.T) {
+	t.Parallel()
+
+	var tree *TOC
+	assert.Nil(t, tree.Clone())
+	assert.Equal(t, &TOC{}, tree.Filter(func(*Item) bool { return true }))
+	assert.Equal(t, &TOC{}, tree.Truncate(1))
+	assert.Equal(t, &TOC{Items: Items{item("Foo", "foo")}}, tree.Under(item("Foo", "foo")))
+	assert.Equal(t, &TOC{}, Merge())
+}
@@ -0,0 +1,162 @@
+package toc
+
+import (
+	"encoding/json"
+)
+
+// tocData is the serialized form of TOC.
+type tocData struct {
+	Items Items `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// itemData is the serialized form of Item.
+//
+// Title and ID are strings so that they don't get
+// base64-encoded by encoding/json.
+type itemData struct {
+	Title  string `json:"title,omitempty" yaml:"title,omitempty"`
+	ID     string `json:"id,omitempty" yaml:"id,omitempty"`
+	URL    string `json:"url,omitempty" yaml:"url,omitempty"`
+	Number []int  `json:"number,omitempty" yaml:"number,omitempty,flow"`
+
+	Level       int  `json:"level,omitempty" yaml:"level,omitempty"`
+	Placeholder bool `json:"placeholder,omitempty" yaml:"placeholder,omitempty"`
+	Line        int  `json:"line,omitempty" yaml:"line,omitempty"`
+
+	Items Items `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+func (t TOC) data() tocData {
+	return tocData(t)
+}
+
+func (t *TOC) setData(d tocData) {
+	*t = TOC(d)
+}
+
+func (i Item) data() itemData {
+	return itemData{
+		Title:  string(i.Title),
+		ID:     string(i.ID),
+		URL:    string(i.URL),
+		Number: i.Number,
+		Items:  i.Items,
+
+		Level:       i.Level,
+		Placeholder: i.Placeholder,
+		Line:        i.Line,
+	}
+}
+
+func (i *Item) setData(d itemData) {
+	*i = Item{
+		Number:      d.Number,
+		Level:       d.Level,
+		Placeholder: d.Placeholder,
+		Line:        d.Line,
+		Items:       d.Items,
+	}
+	if len(d.Title) > 0 {
+		i.Title = []byte(d.Title)
+	}
+	if len(d.ID) > 0 {
+		i.ID = []byte(d.ID)
+	}
+	if len(d.URL) > 0 {
+		i.URL = []byte(d.URL)
+	}
+}
+
+// MarshalJSON encodes the table of contents as JSON.
+//
+// The table of contents is encoded as an object
+// with the top-level items in the "items" field.
+// See Item.MarshalJSON for the format of each item.
+func (t TOC) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.data())
+}
+
+// UnmarshalJSON decodes a table of contents
+// from the JSON produced by MarshalJSON.
+func (t *TOC) UnmarshalJSON(b []byte) error {
+	var d tocData
+	if err := json.Unmarshal(b, &d); err != nil {
+		return err
+	}
+	t.setData(d)
+	return nil
+}
+
+// MarshalJSON encodes the item as JSON.
+//
+// The item is encoded as an object with the following fields.
+// Fields are omitted if they are empty.
+//
+//	{
+//	  "title": "Encoding",   // Title as a string
+//	  "id": "encoding",      // ID as a string
+//	  "url": "api.html",     // URL as a string
+//	  "number": [3, 2, 1],   // Number
+//	  "level": 2,            // Level
+//	  "placeholder": true,   // Placeholder
+//	  "line": 42,            // Line
+//	  "items": [...]         // Items
+//	}
+//
+// RichTitle and Heading are not encoded.
+func (i Item) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.data())
+}
+
+// UnmarshalJSON decodes an item
+// from the JSON produced by MarshalJSON.
+func (i *Item) UnmarshalJSON(b []byte) error {
+	var d itemData
+	if err := json.Unmarshal(b, &d); err != nil {
+		return err
+	}
+	i.setData(d)
+	return nil
+}
+
+// MarshalYAML encodes the table of contents as YAML.
+// It uses the same format as MarshalJSON.
+//
+// This is compatible with gopkg.in/yaml.v2 and gopkg.in/yaml.v3.
+func (t TOC) MarshalYAML() (any, error) {
+	return t.data(), nil
+}
+
+// UnmarshalYAML decodes a table of contents
+// from the YAML produced by MarshalYAML.
+//
+// This is compatible with gopkg.in/yaml.v2 and gopkg.in/yaml.v3.
+func (t *TOC) UnmarshalYAML(unmarshal func(any) error) error {
+	var d tocData
+	if err := unmarshal(&d); err != nil {
+		return err
+	}
+	t.setData(d)
+	return nil
+}
+
+// MarshalYAML encodes the item as YAML.
+// It uses the same format as MarshalJSON.
+//
+// This is compatible with gopkg.in/yaml.v2 and gopkg.in/yaml.v3.
+func (i Item) MarshalYAML() (any, error) {
+	return i.data(), nil
+}
+
+// UnmarshalYAML decodes an item
+// from the YAML produced by MarshalYAML.
+//
+// This is compatible with gopkg.in/yaml.v2 and gopkg.in/yaml.v3.
+func (i *Item) UnmarshalYAML(unmarshal func(any) error) error {
+	var d itemData
+	if err := unmarshal(&d); err != nil {
+		return err
+	}
+	i.setData(d)
+	return nil
+}
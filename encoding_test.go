@@ -0,0 +1,236 @@
+package toc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"gopkg.in/yaml.v3"
+	"pgregory.net/rapid"
+)
+
+func TestTOCEncoding(t *testing.T) {
+	t.Parallel()
+
+	numbered := func(it *Item, number ...int) *Item {
+		it.Number = number
+		return it
+	}
+
+	tests := []struct {
+		desc     string
+		give     *TOC
+		wantJSON string
+		wantYAML string
+	}{
+		{
+			desc:     "empty",
+			give:     &TOC{},
+			wantJSON: `{}`,
+			wantYAML: "{}\n",
+		},
+		{
+			desc: "nested",
+			give: &TOC{
+				Items: Items{
+					item("Foo", "foo",
+						item("Bar", "bar"),
+						item("", "",
+							item("Baz", ""))),
+					item("Qux", "qux"),
+				},
+			},
+			wantJSON: `{"items":[` +
+				`{"title":"Foo","id":"foo","items":[` +
+				`{"title":"Bar","id":"bar"},` +
+				`{"items":[{"title":"Baz"}]}` +
+				`]},` +
+				`{"title":"Qux","id":"qux"}` +
+				`]}`,
+			wantYAML: joinLines(
+				"items:",
+				"    - title: Foo",
+				"      id: foo",
+				"      items:",
+				"        - title: Bar",
+				"          id: bar",
+				"        - items:",
+				"            - title: Baz",
+				"    - title: Qux",
+				"      id: qux",
+			),
+		},
+		{
+			desc: "numbers",
+			give: &TOC{
+				Items: Items{
+					numbered(item("Foo", "foo",
+						numbered(item("Bar", "bar"), 1, 1)), 1),
+				},
+			},
+			wantJSON: `{"items":[` +
+				`{"title":"Foo","id":"foo","number":[1],"items":[` +
+				`{"title":"Bar","id":"bar","number":[1,1]}` +
+				`]}` +
+				`]}`,
+			wantYAML: joinLines(
+				"items:",
+				"    - title: Foo",
+				"      id: foo",
+				"      number: [1]",
+				"      items:",
+				"        - title: Bar",
+				"          id: bar",
+				"          number: [1, 1]",
+			),
+		},
+		{
+			desc: "metadata",
+			give: &TOC{
+				Items: Items{
+					{
+						Level:       1,
+						Placeholder: true,
+						Items: Items{
+							{Title: []byte("Foo"), ID: []byte("foo"), Level: 2, Line: 3},
+						},
+					},
+				},
+			},
+			wantJSON: `{"items":[` +
+				`{"level":1,"placeholder":true,"items":[` +
+				`{"title":"Foo","id":"foo","level":2,"line":3}` +
+				`]}` +
+				`]}`,
+			wantYAML: joinLines(
+				"items:",
+				"    - level: 1",
+				"      placeholder: true",
+				"      items:",
+				"        - title: Foo",
+				"          id: foo",
+				"          level: 2",
+				"          line: 3",
+			),
+		},
+		{
+			desc: "url",
+			give: &TOC{
+				Items: Items{
+					{Title: []byte("Foo"), ID: []byte("foo"), URL: []byte("foo.html#foo")},
+				},
+			},
+			wantJSON: `{"items":[{"title":"Foo","id":"foo","url":"foo.html#foo"}]}`,
+			wantYAML: joinLines(
+				"items:",
+				"    - title: Foo",
+				"      id: foo",
+				"      url: foo.html#foo",
+			),
+		},
+		{
+			desc: "special characters",
+			give: &TOC{
+				Items: Items{
+					item(`"Foo" & <Bar>`, "foo-bar"),
+				},
+			},
+			wantJSON: `{"items":[{"title":"\"Foo\" & <Bar>","id":"foo-bar"}]}`,
+			wantYAML: joinLines(
+				"items:",
+				`    - title: '"Foo" & <Bar>'`,
+				"      id: foo-bar",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("json", func(t *testing.T) {
+				t.Parallel()
+
+				got, err := json.Marshal(tt.give)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.wantJSON, string(got))
+
+				var toc TOC
+				require.NoError(t, json.Unmarshal(got, &toc))
+				assert.Equal(t, tt.give, &toc)
+			})
+
+			t.Run("yaml", func(t *testing.T) {
+				t.Parallel()
+
+				got, err := yaml.Marshal(tt.give)
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantYAML, string(got))
+
+				var toc TOC
+				require.NoError(t, yaml.Unmarshal(got, &toc))
+				assert.Equal(t, tt.give, &toc)
+			})
+		})
+	}
+}
+
+func TestTOCEncoding_errors(t *testing.T) {
+	t.Parallel()
+
+	var toc TOC
+	assert.Error(t, json.Unmarshal([]byte(`{"items": 42}`), &toc))
+	assert.Error(t, json.Unmarshal([]byte(`{"items": [{"title": 42}]}`), &toc))
+	assert.Error(t, yaml.Unmarshal([]byte(`items: 42`), &toc))
+	assert.Error(t, yaml.Unmarshal([]byte(`items: [{title: [1]}]`), &toc))
+}
+
+func TestTOCEncodingRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	rapid.Check(t, testTOCEncodingRoundTrip)
+}
+
+func testTOCEncodingRoundTrip(t *rapid.T) {
+	levels := rapid.SliceOf(rapid.IntRange(1, 6)).Draw(t, "levels")
+	var buf bytes.Buffer
+	for i, level := range levels {
+		buf.WriteString(strings.Repeat("#", level))
+		buf.WriteString(" Heading ")
+		buf.WriteString(strconv.Itoa(i))
+		buf.WriteByte('\n')
+	}
+
+	src := buf.Bytes()
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithAutoHeadingID(),
+	).Parse(text.NewReader(src))
+
+	want, err := Inspect(doc, src, Numbered(rapid.Bool().Draw(t, "numbered")))
+	require.NoError(t, err, "inspect error")
+
+	jsonData, err := json.Marshal(want)
+	require.NoError(t, err)
+	var gotJSON TOC
+	require.NoError(t, json.Unmarshal(jsonData, &gotJSON))
+	assert.Equal(t, want, &gotJSON, "JSON round trip:\n%s", jsonData)
+
+	yamlData, err := yaml.Marshal(want)
+	require.NoError(t, err)
+	var gotYAML TOC
+	require.NoError(t, yaml.Unmarshal(yamlData, &gotYAML))
+	assert.Equal(t, want, &gotYAML, "YAML round trip:\n%s", yamlData)
+}
+
+func joinLines(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -1,6 +1,8 @@
 package toc_test
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 
 	"github.com/yuin/goldmark"
@@ -72,3 +74,82 @@ Bye
 	// </li>
 	// </ul>
 }
+
+func ExampleFromContext() {
+	src := []byte(`
+# A section
+
+Hello
+
+## A sub-section
+
+Bye
+`)
+
+	markdown := goldmark.New(
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithExtensions(&toc.Extender{
+			// Don't add the table of contents to the document.
+			Position: toc.PositionNone,
+		}),
+	)
+
+	// Use a parser.Context to retrieve the table of contents
+	// after the document is converted.
+	ctx := parser.NewContext()
+	var body bytes.Buffer
+	if err := markdown.Convert(src, &body, parser.WithContext(ctx)); err != nil {
+		panic(err)
+	}
+
+	// Render the table of contents separately,
+	// e.g. into a sidebar.
+	tree := toc.FromContext(ctx)
+	if err := markdown.Renderer().Render(os.Stdout, src, toc.RenderList(tree)); err != nil {
+		panic(err)
+	}
+
+	fmt.Println("---")
+	fmt.Print(body.String())
+
+	// Output:
+	// <ul>
+	// <li>
+	// <a href="#a-section">A section</a><ul>
+	// <li>
+	// <a href="#a-sub-section">A sub-section</a></li>
+	// </ul>
+	// </li>
+	// </ul>
+	// ---
+	// <h1 id="a-section">A section</h1>
+	// <p>Hello</p>
+	// <h2 id="a-sub-section">A sub-section</h2>
+	// <p>Bye</p>
+}
+
+func ExampleTOC_Flatten() {
+	src := []byte(`
+# Install
+## Linux
+## macOS
+# Usage
+`)
+
+	markdown := goldmark.New(goldmark.WithParserOptions(parser.WithAutoHeadingID()))
+	doc := markdown.Parser().Parse(text.NewReader(src))
+	tree, err := toc.Inspect(doc, src)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, flat := range tree.Flatten() {
+		fmt.Printf("%d %s\n", flat.Depth, flat.Item.Title)
+	}
+
+	// Output:
+	// 1 Install
+	// 2 Linux
+	// 2 macOS
+	// 1 Usage
+}
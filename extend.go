@@ -2,7 +2,9 @@ package toc
 
 import (
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/util"
 )
 
@@ -50,6 +52,25 @@ type Extender struct {
 	// Defaults to 0 (no limit) if unspecified.
 	MaxDepth int
 
+	// RelativeMinDepth is the minimum depth of the table of contents,
+	// counted from the shallowest heading in the document.
+	// See the documentation for RelativeMinDepth for more information.
+	//
+	// Defaults to 0 (no limit) if unspecified.
+	RelativeMinDepth int
+
+	// RelativeMaxDepth is the maximum depth of the table of contents,
+	// counted from the shallowest heading in the document.
+	// See the documentation for RelativeMaxDepth for more information.
+	//
+	// Defaults to 0 (no limit) if unspecified.
+	RelativeMaxDepth int
+
+	// Normalize specifies whether the table of contents should be shifted
+	// so that the shallowest heading in it is at the top level.
+	// See the documentation for Normalize for more information.
+	Normalize bool
+
 	// ListID is the id for the list of TOC items rendered in the HTML.
 	//
 	// See the documentation for Transformer.ListID for more information.
@@ -60,11 +81,177 @@ type Extender struct {
 	// See the documentation for Transformer.TitleID for more information.
 	TitleID string
 
+	// BaseURL is placed before links to headings
+	// in the table of contents.
+	//
+	// See the documentation for ListRenderer.BaseURL for more information.
+	BaseURL string
+
+	// LinkFunc, if set, decides the link destination
+	// for each item in the table of contents.
+	//
+	// See the documentation for ListRenderer.LinkFunc for more information.
+	LinkFunc LinkFunc
+
 	// Compact controls whether empty items should be removed
 	// from the table of contents.
 	//
 	// See the documentation for Compact for more information.
 	Compact bool
+
+	// MinItems is the minimum number of headings that
+	// the table of contents must list to be added to the document.
+	//
+	// See the documentation for Transformer.MinItems for more information.
+	MinItems int
+
+	// Marker is a placeholder that marks where the table of contents
+	// should be placed in the document, e.g. "[TOC]" or "<!-- toc -->".
+	//
+	// See the documentation for Transformer.Marker for more information.
+	Marker string
+
+	// Position specifies where the table of contents is placed
+	// if the document does not contain a Marker.
+	//
+	// Defaults to PositionTop.
+	Position Position
+
+	// Anchor, if set, picks where the table of contents is placed
+	// if the document does not contain a Marker.
+	//
+	// See the documentation for Transformer.Anchor
+	// for more information.
+	Anchor func(doc *ast.Document) ast.Node
+
+	// LeadingTitle specifies what to do with the leading title
+	// of the document, e.g. a single <h1> at the top.
+	//
+	// Defaults to LeadingTitleKeep.
+	LeadingTitle LeadingTitle
+
+	// Nav specifies whether the table of contents should be wrapped
+	// in a <nav> element.
+	//
+	// If set, the table of contents is added to the document as a Node,
+	// and an HTMLRenderer is installed to render it.
+	// Chapter tables of contents (see ChapterLevel) are wrapped too.
+	Nav bool
+
+	// NavID is the id of the <nav> element.
+	// This has no effect unless Nav is set.
+	NavID string
+
+	// NavClass is the class of the <nav> element.
+	// This has no effect unless Nav is set.
+	NavClass string
+
+	// NavLabel is the aria-label of the <nav> element.
+	// This has no effect unless Nav is set.
+	NavLabel string
+
+	// Filter, if set, decides whether a heading
+	// should be included in the table of contents.
+	// See the documentation for Filter for more information.
+	Filter func(h *ast.Heading, src []byte) bool
+
+	// IgnoreClass is the class that marks a heading
+	// to be left out of the table of contents.
+	// See the documentation for Transformer.IgnoreClass for more information.
+	IgnoreClass string
+
+	// IgnoreAttribute is the attribute that marks a heading
+	// to be left out of the table of contents if it's set to "false".
+	// See the documentation for Transformer.IgnoreAttribute for more information.
+	IgnoreAttribute string
+
+	// IgnoreComment is the text of an HTML comment that marks
+	// the heading right after it to be left out of the table of contents.
+	// See the documentation for Transformer.IgnoreComment for more information.
+	IgnoreComment string
+
+	// Numbered specifies whether items in the table of contents
+	// should be numbered, e.g. "3.2.1 Encoding".
+	// See the documentation for Numbered for more information.
+	Numbered bool
+
+	// NumberFormat formats section numbers.
+	// This has no effect unless Numbered is set.
+	//
+	// Defaults to FormatNumber(Decimal), which renders "3.2.1".
+	NumberFormat NumberFormat
+
+	// NumberHeadings specifies whether the headings in the document
+	// should also be prefixed with their section numbers.
+	// This has no effect unless Numbered is set.
+	NumberHeadings bool
+
+	// RichTitles specifies whether items in the table of contents
+	// should keep the inline formatting of their headings,
+	// e.g. code spans and emphasis.
+	// See the documentation for RichTitles for more information.
+	RichTitles bool
+
+	// TextExtractors specifies how to extract the text of titles
+	// from inline nodes of different kinds in headings.
+	// See the documentation for ExtractText for more information.
+	TextExtractors map[ast.NodeKind]TextExtractor
+
+	// LabelAttribute is the heading attribute
+	// that overrides the title of a heading in the table of contents.
+	// See the documentation for LabelAttribute for more information.
+	//
+	// Defaults to "toc-label" if unspecified.
+	LabelAttribute string
+
+	// TitleFunc, if set, changes the titles
+	// of items in the table of contents.
+	// See the documentation for TitleFunc for more information.
+	TitleFunc func(h *ast.Heading, title []byte) []byte
+
+	// HTMLHeadings specifies whether headings written in raw HTML,
+	// e.g. <h2 id="foo">Foo</h2>, should be included
+	// in the table of contents.
+	// See the documentation for HTMLHeadings for more information.
+	HTMLHeadings bool
+
+	// TopLevelOnly specifies whether only headings at the top level
+	// of the document should be included in the table of contents.
+	// See the documentation for TopLevelOnly for more information.
+	TopLevelOnly bool
+
+	// ExcludeContainers lists kinds of block nodes,
+	// e.g. ast.KindBlockquote, whose headings should be left out
+	// of the table of contents.
+	// See the documentation for ExcludeContainers for more information.
+	ExcludeContainers []ast.NodeKind
+
+	// ChapterLevel, if set, adds a table of contents
+	// for each chapter of the document: a heading at this level.
+	//
+	// See the documentation for Transformer.ChapterLevel
+	// for more information.
+	ChapterLevel int
+
+	// ChapterTitle is the title placed before
+	// each chapter's table of contents.
+	ChapterTitle string
+
+	// ChapterMaxDepth is the maximum depth of
+	// each chapter's table of contents,
+	// counted from the headings right under the chapter.
+	ChapterMaxDepth int
+
+	// ChapterMinItems is the minimum number of items
+	// that a chapter's table of contents must have to be added.
+	ChapterMinItems int
+
+	// Configure, if set, is called for each document
+	// to change the table of contents for only that document.
+	//
+	// See the documentation for Transformer.Configure
+	// for more information.
+	Configure func(ctx parser.Context, t *Transformer) bool
 }
 
 // Extend adds support for rendering a table of contents to the provided
@@ -73,14 +260,56 @@ func (e *Extender) Extend(md goldmark.Markdown) {
 	md.Parser().AddOptions(
 		parser.WithASTTransformers(
 			util.Prioritized(&Transformer{
-				Title:      e.Title,
-				TitleDepth: e.TitleDepth,
-				MinDepth:   e.MinDepth,
-				MaxDepth:   e.MaxDepth,
-				ListID:     e.ListID,
-				TitleID:    e.TitleID,
-				Compact:    e.Compact,
+				Title:             e.Title,
+				TitleDepth:        e.TitleDepth,
+				MinDepth:          e.MinDepth,
+				MaxDepth:          e.MaxDepth,
+				RelativeMinDepth:  e.RelativeMinDepth,
+				RelativeMaxDepth:  e.RelativeMaxDepth,
+				Normalize:         e.Normalize,
+				ListID:            e.ListID,
+				TitleID:           e.TitleID,
+				BaseURL:           e.BaseURL,
+				LinkFunc:          e.LinkFunc,
+				Compact:           e.Compact,
+				MinItems:          e.MinItems,
+				Marker:            e.Marker,
+				Position:          e.Position,
+				Anchor:            e.Anchor,
+				LeadingTitle:      e.LeadingTitle,
+				Wrap:              e.Nav,
+				Filter:            e.Filter,
+				IgnoreClass:       e.IgnoreClass,
+				IgnoreAttribute:   e.IgnoreAttribute,
+				IgnoreComment:     e.IgnoreComment,
+				Numbered:          e.Numbered,
+				NumberFormat:      e.NumberFormat,
+				NumberHeadings:    e.NumberHeadings,
+				RichTitles:        e.RichTitles,
+				TextExtractors:    e.TextExtractors,
+				LabelAttribute:    e.LabelAttribute,
+				TitleFunc:         e.TitleFunc,
+				HTMLHeadings:      e.HTMLHeadings,
+				TopLevelOnly:      e.TopLevelOnly,
+				ExcludeContainers: e.ExcludeContainers,
+				ChapterLevel:      e.ChapterLevel,
+				ChapterTitle:      e.ChapterTitle,
+				ChapterMaxDepth:   e.ChapterMaxDepth,
+				ChapterMinItems:   e.ChapterMinItems,
+				Configure:         e.Configure,
 			}, 100),
 		),
 	)
+
+	if e.Nav {
+		md.Renderer().AddOptions(
+			renderer.WithNodeRenderers(
+				util.Prioritized(&HTMLRenderer{
+					ID:    e.NavID,
+					Class: e.NavClass,
+					Label: e.NavLabel,
+				}, 100),
+			),
+		)
+	}
 }
@@ -0,0 +1,197 @@
+package toc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// HTMLHeadings instructs Inspect to include headings written in raw HTML
+// in the table of contents.
+//
+// For example, given the following:
+//
+//	# Foo
+//
+//	<h2 id="bar">Bar <em>baz</em></h2>
+//
+// HTMLHeadings(true) will result in the following:
+//
+//	TOC{Items: ...}
+//	 |
+//	 +--- &Item{Title: "Foo", ID: "foo", Items: ...}
+//	       |
+//	       +--- &Item{Title: "Bar baz", ID: "bar"}
+//
+// <h1> through <h6> tags are picked up from HTML blocks,
+// and from paragraphs that contain inline HTML.
+// The id attribute of the tag is used as the item's ID,
+// and the text inside the tag, without any markup, as its title.
+// HTML headings without an id are listed without a link.
+//
+// Other attributes of the tag are available to
+// IgnoreClass, IgnoreAttribute, and LabelAttribute.
+// For example, the following heading is left out by default:
+//
+//	<h2 class="notoc">License</h2>
+//
+// Headings from HTML are passed to Filter and TitleFunc
+// as *ast.Heading nodes that are not part of the document.
+// Their Parent is the parent of the HTML block they came from.
+func HTMLHeadings(include bool) InspectOption {
+	return htmlHeadingsOption(include)
+}
+
+type htmlHeadingsOption bool
+
+func (h htmlHeadingsOption) apply(opts *inspectOptions) {
+	opts.htmlHeadings = bool(h)
+}
+
+func (h htmlHeadingsOption) String() string {
+	return fmt.Sprintf("HTMLHeadings(%v)", bool(h))
+}
+
+var (
+	// Matches <h1>...</h1> through <h6>...</h6>.
+	// Go's regexp does not support backreferences
+	// so the levels of the opening and closing tags
+	// are captured separately and must be compared.
+	_htmlHeadingRx = regexp.MustCompile(`(?is)<h([1-6])(\s[^>]*)?>(.*?)</h([1-6])\s*>`)
+
+	// Matches attributes inside a tag:
+	// name, name=value, name="value", and name='value'.
+	_htmlAttributeRx = regexp.MustCompile(`([^\s"'<>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+
+	// Matches any tag or comment.
+	_htmlTagRx = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+)
+
+// htmlHeadings returns headings found in the raw HTML
+// of the given HTML block or paragraph.
+//
+// The returned headings are not part of the document.
+func htmlHeadings(src []byte, n ast.Node) []*ast.Heading {
+	// Lines of a block are not always contiguous in the source
+	// (e.g. inside block quotes),
+	// so we record where each segment starts to map offsets back.
+	var (
+		buf    bytes.Buffer
+		segs   []text.Segment
+		starts []int // starts[i] is the offset of segs[i] in buf
+	)
+	write := func(seg text.Segment, value []byte) {
+		segs = append(segs, seg)
+		starts = append(starts, buf.Len())
+		buf.Write(value)
+	}
+
+	switch n := n.(type) {
+	case *ast.HTMLBlock:
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			seg := lines.At(i)
+			write(seg, seg.Value(src))
+		}
+		if n.HasClosure() {
+			write(n.ClosureLine, n.ClosureLine.Value(src))
+		}
+
+	case *ast.Paragraph:
+		if !hasRawHTML(n) {
+			return nil
+		}
+
+		// Only raw HTML in the paragraph can form tags.
+		// Other text, e.g. inside code spans, is escaped
+		// so that it can only be the text of a heading.
+		_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+
+			switch c := c.(type) {
+			case *ast.RawHTML:
+				for i := 0; i < c.Segments.Len(); i++ {
+					seg := c.Segments.At(i)
+					write(seg, seg.Value(src))
+				}
+			case *ast.Text:
+				value := html.EscapeString(string(c.Segment.Value(src)))
+				if c.SoftLineBreak() || c.HardLineBreak() {
+					value += "\n"
+				}
+				write(c.Segment, []byte(value))
+			}
+			return ast.WalkContinue, nil
+		})
+
+	default:
+		return nil
+	}
+
+	srcPos := func(off int) int {
+		i := sort.SearchInts(starts, off+1) - 1
+		return segs[i].Start + min(off-starts[i], segs[i].Len())
+	}
+
+	var headings []*ast.Heading
+	raw := buf.Bytes()
+	for _, m := range _htmlHeadingRx.FindAllSubmatchIndex(raw, -1) {
+		level := raw[m[2]]
+		if level != raw[m[8]] {
+			continue // mismatched tags, e.g. <h2>...</h3>
+		}
+
+		h := ast.NewHeading(int(level - '0'))
+		h.SetParent(n.Parent())
+		h.SetPos(srcPos(m[0]))
+
+		if m[4] >= 0 {
+			setHTMLAttributes(h, raw[m[4]:m[5]])
+		}
+
+		title := _htmlTagRx.ReplaceAll(raw[m[6]:m[7]], nil)
+		title = []byte(strings.Join(strings.Fields(html.UnescapeString(string(title))), " "))
+		if len(title) > 0 {
+			h.AppendChild(h, ast.NewString(title))
+		}
+
+		headings = append(headings, h)
+	}
+	return headings
+}
+
+// setHTMLAttributes sets attributes from the attribute portion
+// of an HTML tag on the given node.
+// Attributes without values are set to empty strings.
+func setHTMLAttributes(n ast.Node, attrs []byte) {
+	for _, m := range _htmlAttributeRx.FindAllSubmatch(attrs, -1) {
+		name := bytes.ToLower(m[1])
+
+		var value []byte
+		for _, v := range m[2:] {
+			if v != nil {
+				value = v
+				break
+			}
+		}
+
+		n.SetAttribute(name, []byte(html.UnescapeString(string(value))))
+	}
+}
+
+func hasRawHTML(n ast.Node) bool {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Kind() == ast.KindRawHTML {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package toc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestHTMLHeadings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give []string // lines of a doc
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "block",
+			give: []string{
+				"# Foo",
+				"",
+				`<h2 id="bar">Bar</h2>`,
+				"",
+				"## Baz",
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar"),
+					item("Baz", "baz"),
+				),
+			},
+		},
+		{
+			desc: "nested under html",
+			give: []string{
+				`<h1 id="foo">Foo</h1>`,
+				"",
+				"## Bar",
+				"",
+				`<h3 id='baz'>Baz</h3>`,
+				"",
+				"# Qux",
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar",
+						item("Baz", "baz"),
+					),
+				),
+				item("Qux", "qux"),
+			},
+		},
+		{
+			desc: "multiple in a block",
+			give: []string{
+				`<div>`,
+				`<h2 id="foo">Foo</h2>`,
+				`<p>text</p>`,
+				`<H3 ID=bar>Bar</H3>`,
+				`</div>`,
+			},
+			want: Items{
+				item("", "",
+					item("Foo", "foo",
+						item("Bar", "bar"),
+					),
+				),
+			},
+		},
+		{
+			desc: "markup and entities",
+			give: []string{
+				`<h1 id="foo">Foo <code>bar</code> &amp;`,
+				`  <!-- comment -->baz</h1>`,
+			},
+			want: Items{
+				item("Foo bar & baz", "foo"),
+			},
+		},
+		{
+			desc: "no id",
+			give: []string{
+				`<h1>Foo</h1>`,
+			},
+			want: Items{
+				item("Foo", ""),
+			},
+		},
+		{
+			desc: "mismatched tags",
+			give: []string{
+				`<h1 id="foo">Foo</h2>`,
+				"",
+				"# Bar",
+			},
+			want: Items{
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "inline",
+			give: []string{
+				`Some text <h2 id="foo">Foo</h2> more text.`,
+			},
+			want: Items{
+				item("", "",
+					item("Foo", "foo"),
+				),
+			},
+		},
+		{
+			desc: "inline code span",
+			give: []string{
+				"Use `` `<h2 id=\"x\">X</h2>` `` in <b>HTML</b>.",
+			},
+		},
+		{
+			desc: "inline heading with code span",
+			give: []string{
+				"Text <h2 id=\"foo\">Use `<b>` tags</h2> more text.",
+			},
+			want: Items{
+				item("", "",
+					item("Use <b> tags", "foo"),
+				),
+			},
+		},
+		{
+			desc: "block quote",
+			give: []string{
+				`> <h1 id="foo">`,
+				`> Foo`,
+				`> </h1>`,
+			},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "ignored",
+			give: []string{
+				`<h1 id="foo" class="x notoc">Foo</h1>`,
+				"",
+				"## Bar",
+				"",
+				`<h1 id="baz" data-toc="false">Baz</h1>`,
+				"",
+				`<h1 id="qux">Qux</h1>`,
+			},
+			want: Items{
+				item("Qux", "qux"),
+			},
+		},
+		{
+			desc: "label",
+			give: []string{
+				`<h1 id="foo" toc-label="F &amp; B">Foo and Bar</h1>`,
+			},
+			want: Items{
+				item("F & B", "foo"),
+			},
+		},
+		{
+			desc: "depth",
+			give: []string{
+				`<h1 id="foo">Foo</h1>`,
+				`<h2 id="bar">Bar</h2>`,
+				`<h3 id="baz">Baz</h3>`,
+			},
+			opts: []InspectOption{MinDepth(2), MaxDepth(2)},
+			want: Items{
+				item("", "",
+					item("Bar", "bar"),
+				),
+			},
+		},
+		{
+			desc: "disabled",
+			give: []string{
+				`<h1 id="foo">Foo</h1>`,
+				"",
+				"# Bar",
+			},
+			opts: []InspectOption{HTMLHeadings(false)},
+			want: Items{
+				item("Bar", "bar"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src))
+
+			opts := append([]InspectOption{HTMLHeadings(true)}, tt.opts...)
+			got, err := Inspect(doc, src, opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestHTMLHeadings_filter(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(strings.Join([]string{
+		`<h1 id="foo">Foo</h1>`,
+		"",
+		`> <h1 id="bar">Bar</h1>`,
+	}, "\n") + "\n")
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+	).Parse(text.NewReader(src))
+
+	var positions []int
+	got, err := Inspect(doc, src,
+		HTMLHeadings(true),
+		Filter(func(h *ast.Heading, _ []byte) bool {
+			positions = append(positions, h.Pos())
+			return h.Parent().Kind() != ast.KindBlockquote
+		}),
+	)
+	require.NoError(t, err, "inspect error")
+	assert.Equal(t, &TOC{Items: Items{item("Foo", "foo")}}, got)
+	assert.Equal(t, []int{0, 25}, positions)
+}
@@ -3,7 +3,8 @@ package toc
 import (
 	"bytes"
 	"fmt"
-	"io"
+	"slices"
+	"strings"
 
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/util"
@@ -18,8 +19,55 @@ type inspectOptions struct {
 	minDepth int
 	maxDepth int
 	compact  bool
+
+	ignoreClass     string
+	ignoreAttribute string
+	ignoreComment   string
+
+	filters []func(*ast.Heading, []byte) bool
+
+	numbered bool
+
+	richTitles bool
+
+	// metadata records where each item came from.
+	metadata bool
+
+	textExtractors textExtractors
+
+	labelAttribute string
+	titleFuncs     []func(*ast.Heading, []byte) []byte
+
+	htmlHeadings bool
+
+	relativeMinDepth int
+	relativeMaxDepth int
+	normalize        bool
+
+	omitLeadingTitle bool
+
+	// section, if set, limits the table of contents
+	// to the headings under this heading.
+	section *ast.Heading
+
+	// onLeadingTitle, if set, is called with the leading title heading
+	// if the document has one.
+	onLeadingTitle func(*ast.Heading)
+
+	topLevelOnly      bool
+	excludeContainers map[ast.NodeKind]struct{}
+
+	// onItem, if set, is called with each item built from a heading.
+	onItem func(*Item, *ast.Heading)
 }
 
+const (
+	_defaultIgnoreClass     = "notoc"
+	_defaultIgnoreAttribute = "data-toc"
+	_defaultIgnoreComment   = "toc:ignore"
+	_defaultLabelAttribute  = "toc-label"
+)
+
 // MinDepth limits the depth of the table of contents.
 // Headings with a level lower than the specified depth will be ignored.
 //
@@ -112,6 +160,122 @@ func (d maxDepthOption) String() string {
 	return fmt.Sprintf("MaxDepth(%d)", int(d))
 }
 
+// RelativeMinDepth is like MinDepth,
+// but it counts depth from the shallowest heading in the document
+// instead of from <h1>.
+//
+// For example, given the following:
+//
+//	## Foo
+//	### Bar
+//	#### Baz
+//
+// RelativeMinDepth(2) will leave out "Foo"
+// because it's at relative depth 1.
+// "Bar" and "Baz" are at relative depths 2 and 3.
+//
+// The shallowest heading is found after applying MinDepth and MaxDepth,
+// and leaving out ignored and filtered headings.
+//
+// A value of 0 or less will result in no limit.
+//
+// The default is no limit.
+func RelativeMinDepth(depth int) InspectOption {
+	return relativeMinDepthOption(depth)
+}
+
+type relativeMinDepthOption int
+
+func (d relativeMinDepthOption) apply(opts *inspectOptions) {
+	opts.relativeMinDepth = int(d)
+}
+
+func (d relativeMinDepthOption) String() string {
+	return fmt.Sprintf("RelativeMinDepth(%d)", int(d))
+}
+
+// RelativeMaxDepth is like MaxDepth,
+// but it counts depth from the shallowest heading in the document
+// instead of from <h1>.
+//
+// For example, given the following:
+//
+//	## Foo
+//	### Bar
+//	#### Baz
+//
+// RelativeMaxDepth(2) will leave out "Baz"
+// because it's at relative depth 3.
+// This is the same as MaxDepth(3) for this document,
+// and MaxDepth(2) for a document that starts with an <h1>.
+//
+// The shallowest heading is found after applying MinDepth and MaxDepth,
+// and leaving out ignored and filtered headings.
+//
+// A value of 0 or less will result in no limit.
+//
+// The default is no limit.
+func RelativeMaxDepth(depth int) InspectOption {
+	return relativeMaxDepthOption(depth)
+}
+
+type relativeMaxDepthOption int
+
+func (d relativeMaxDepthOption) apply(opts *inspectOptions) {
+	opts.relativeMaxDepth = int(d)
+}
+
+func (d relativeMaxDepthOption) String() string {
+	return fmt.Sprintf("RelativeMaxDepth(%d)", int(d))
+}
+
+// Normalize instructs Inspect to shift all items in the table of contents
+// so that the shallowest heading included in it is at the top level.
+//
+// For example, given the following:
+//
+//	## Foo
+//	### Bar
+//	## Baz
+//
+// Normalize(false), which is the default, will result in the following:
+//
+//	TOC{Items: ...}
+//	 |
+//	 +--- &Item{Title: "", ...}
+//	       |
+//	       +--- &Item{Title: "Foo", ...}
+//	       |     |
+//	       |     +--- &Item{Title: "Bar", ...}
+//	       |
+//	       +--- &Item{Title: "Baz", ...}
+//
+// Whereas, Normalize(true) will result in the following:
+//
+//	TOC{Items: ...}
+//	 |
+//	 +--- &Item{Title: "Foo", ...}
+//	 |     |
+//	 |     +--- &Item{Title: "Bar", ...}
+//	 |
+//	 +--- &Item{Title: "Baz", ...}
+//
+// Unlike Compact, this keeps empty items
+// for gaps between heading levels deeper in the document.
+func Normalize(normalize bool) InspectOption {
+	return normalizeOption(normalize)
+}
+
+type normalizeOption bool
+
+func (n normalizeOption) apply(opts *inspectOptions) {
+	opts.normalize = bool(n)
+}
+
+func (n normalizeOption) String() string {
+	return fmt.Sprintf("Normalize(%v)", bool(n))
+}
+
 // Compact instructs Inspect to remove empty items from the table of contents.
 // Children of removed items will be promoted to the parent item.
 //
@@ -173,6 +337,378 @@ func (c compactOption) String() string {
 	return fmt.Sprintf("Compact(%v)", bool(c))
 }
 
+// IgnoreClass specifies a class that marks a heading
+// to be left out of the table of contents.
+// Headings nested under an ignored heading are left out as well.
+//
+// Headings only have classes if the parser was configured with
+// parser.WithHeadingAttribute. For example, the following heading
+// will be ignored with the default configuration.
+//
+//	## License {.notoc}
+//
+// An empty class disables this check.
+//
+// The default is "notoc".
+func IgnoreClass(class string) InspectOption {
+	return ignoreClassOption(class)
+}
+
+type ignoreClassOption string
+
+func (c ignoreClassOption) apply(opts *inspectOptions) {
+	opts.ignoreClass = string(c)
+}
+
+func (c ignoreClassOption) String() string {
+	return fmt.Sprintf("IgnoreClass(%q)", string(c))
+}
+
+// IgnoreAttribute specifies an attribute that marks a heading
+// to be left out of the table of contents if it's set to "false".
+// Headings nested under an ignored heading are left out as well.
+//
+// Headings only have attributes if the parser was configured with
+// parser.WithHeadingAttribute. For example, the following heading
+// will be ignored with the default configuration.
+//
+//	## License {data-toc="false"}
+//
+// An empty name disables this check.
+//
+// The default is "data-toc".
+func IgnoreAttribute(name string) InspectOption {
+	return ignoreAttributeOption(name)
+}
+
+type ignoreAttributeOption string
+
+func (a ignoreAttributeOption) apply(opts *inspectOptions) {
+	opts.ignoreAttribute = string(a)
+}
+
+func (a ignoreAttributeOption) String() string {
+	return fmt.Sprintf("IgnoreAttribute(%q)", string(a))
+}
+
+// IgnoreComment specifies the text of an HTML comment that marks
+// the heading on the line right after it
+// to be left out of the table of contents.
+// Headings nested under an ignored heading are left out as well.
+//
+// For example, the following heading
+// will be ignored with the default configuration.
+//
+//	<!-- toc:ignore -->
+//	## License
+//
+// Whitespace around the text inside the comment is ignored.
+// An empty text disables this check.
+//
+// The default is "toc:ignore".
+func IgnoreComment(text string) InspectOption {
+	return ignoreCommentOption(text)
+}
+
+type ignoreCommentOption string
+
+func (c ignoreCommentOption) apply(opts *inspectOptions) {
+	opts.ignoreComment = string(c)
+}
+
+func (c ignoreCommentOption) String() string {
+	return fmt.Sprintf("IgnoreComment(%q)", string(c))
+}
+
+// Filter specifies a function that decides
+// whether a heading should be included in the table of contents.
+// The function is called with each heading that Inspect considers
+// and the source of the document.
+// Headings for which it returns false are left out.
+//
+// For example, the following leaves out headings inside block quotes.
+//
+//	toc.Filter(func(h *ast.Heading, _ []byte) bool {
+//		return h.Parent().Kind() != ast.KindBlockquote
+//	})
+//
+// Unlike headings left out with IgnoreClass or IgnoreAttribute,
+// headings nested under a filtered heading are still considered.
+// They are placed under an empty item in place of the filtered heading.
+// Use Compact to remove these.
+//
+// Filtered headings don't count towards
+// RelativeMinDepth, RelativeMaxDepth, and Normalize.
+//
+// If multiple Filter options are provided,
+// a heading must satisfy all of them to be included.
+func Filter(keep func(h *ast.Heading, src []byte) bool) InspectOption {
+	return filterOption(keep)
+}
+
+type filterOption func(*ast.Heading, []byte) bool
+
+func (f filterOption) apply(opts *inspectOptions) {
+	if f != nil {
+		opts.filters = append(opts.filters, f)
+	}
+}
+
+func (f filterOption) String() string {
+	return "Filter(...)"
+}
+
+// Numbered instructs Inspect to record hierarchical section numbers
+// on items in the table of contents.
+//
+// For example, given the following:
+//
+//	# Foo
+//	## Bar
+//	#### Baz
+//	## Qux
+//
+// Numbered(true) will result in the following:
+//
+//	TOC{Items: ...}
+//	 |
+//	 +--- &Item{Title: "Foo", Number: [1], ...}
+//	       |
+//	       +--- &Item{Title: "Bar", Number: [1 1], ...}
+//	       |     |
+//	       |     +--- &Item{Title: "", ...}
+//	       |           |
+//	       |           +--- &Item{Title: "Baz", Number: [1 1 1], ...}
+//	       |
+//	       +--- &Item{Title: "Qux", Number: [1 2], ...}
+//
+// Notice that empty items do not get a number,
+// and do not affect the numbers of other items.
+//
+// Use ListRenderer.NumberFormat to control how numbers are rendered.
+func Numbered(numbered bool) InspectOption {
+	return numberedOption(numbered)
+}
+
+type numberedOption bool
+
+func (n numberedOption) apply(opts *inspectOptions) {
+	opts.numbered = bool(n)
+}
+
+func (n numberedOption) String() string {
+	return fmt.Sprintf("Numbered(%v)", bool(n))
+}
+
+// RichTitles instructs Inspect to record the inline content of headings
+// on items in the table of contents in Item.RichTitle.
+//
+// For example, given the following:
+//
+//	## The `Render` method
+//	## *Why* this matters
+//
+// RichTitles(true) will result in items that ListRenderer renders as:
+//
+//   - [The `Render` method](#the-render-method)
+//   - [*Why* this matters](#why-this-matters)
+//
+// Whereas by default, the TOC will have only the plain text:
+//
+//   - [The Render method](#the-render-method)
+//   - [Why this matters](#why-this-matters)
+//
+// Links and images inside headings are replaced with their contents
+// so that the TOC never has a link inside another link.
+func RichTitles(rich bool) InspectOption {
+	return richTitlesOption(rich)
+}
+
+type richTitlesOption bool
+
+func (r richTitlesOption) apply(opts *inspectOptions) {
+	opts.richTitles = bool(r)
+}
+
+func (r richTitlesOption) String() string {
+	return fmt.Sprintf("RichTitles(%v)", bool(r))
+}
+
+// Metadata instructs Inspect to record where each item
+// in the table of contents came from:
+// the level and line number of its heading,
+// a reference to the heading itself,
+// and whether the item is a placeholder for a skipped level.
+//
+// For example, given the following:
+//
+//	# Foo
+//
+//	### Bar
+//
+// Metadata(true) will result in:
+//
+//	Foo     Level: 1, Line: 1
+//	(empty) Level: 2, Placeholder: true
+//	Bar     Level: 3, Line: 3
+//
+// See the documentation for Item for more information.
+func Metadata(metadata bool) InspectOption {
+	return metadataOption(metadata)
+}
+
+type metadataOption bool
+
+func (m metadataOption) apply(opts *inspectOptions) {
+	opts.metadata = bool(m)
+}
+
+func (m metadataOption) String() string {
+	return fmt.Sprintf("Metadata(%v)", bool(m))
+}
+
+// TopLevelOnly instructs Inspect to include only headings
+// that are direct children of the node being inspected,
+// e.g. the document.
+// Headings inside block quotes, lists,
+// and other containers are left out.
+//
+// For example, given the following:
+//
+//	# Foo
+//
+//	> # Bar
+//
+//	- # Baz
+//
+// TopLevelOnly(true) will result in the following:
+//
+//	TOC{Items: ...}
+//	 |
+//	 +--- &Item{Title: "Foo", ID: "foo"}
+//
+// Use ExcludeContainers to leave out only some kinds of containers.
+func TopLevelOnly(topLevel bool) InspectOption {
+	return topLevelOnlyOption(topLevel)
+}
+
+type topLevelOnlyOption bool
+
+func (o topLevelOnlyOption) apply(opts *inspectOptions) {
+	opts.topLevelOnly = bool(o)
+}
+
+func (o topLevelOnlyOption) String() string {
+	return fmt.Sprintf("TopLevelOnly(%v)", bool(o))
+}
+
+// ExcludeContainers instructs Inspect to leave out headings
+// inside block nodes of the given kinds.
+//
+// For example, the following leaves out headings
+// inside block quotes and list items.
+//
+//	toc.ExcludeContainers(ast.KindBlockquote, ast.KindListItem)
+//
+// This works with containers added by extensions as well,
+// e.g. admonitions or details blocks,
+// given the NodeKind that the extension registered for them.
+//
+// If multiple ExcludeContainers options are provided,
+// headings inside containers of any of the given kinds are left out.
+func ExcludeContainers(kinds ...ast.NodeKind) InspectOption {
+	return excludeContainersOption(kinds)
+}
+
+type excludeContainersOption []ast.NodeKind
+
+func (o excludeContainersOption) apply(opts *inspectOptions) {
+	if len(o) == 0 {
+		return
+	}
+
+	if opts.excludeContainers == nil {
+		opts.excludeContainers = make(map[ast.NodeKind]struct{}, len(o))
+	}
+	for _, kind := range o {
+		opts.excludeContainers[kind] = struct{}{}
+	}
+}
+
+func (o excludeContainersOption) String() string {
+	names := make([]string, len(o))
+	for i, kind := range o {
+		names[i] = kind.String()
+	}
+	return fmt.Sprintf("ExcludeContainers(%v)", strings.Join(names, ", "))
+}
+
+// OmitLeadingTitle instructs Inspect to leave out the title of the document
+// from the table of contents, and promote the headings under it.
+//
+// The title is the first heading in the document
+// if no other heading is at the same or a lower level.
+// For example, given the following:
+//
+//	# Title
+//	## Foo
+//	### Bar
+//	## Baz
+//
+// OmitLeadingTitle(true) will result in the following:
+//
+//	TOC{Items: ...}
+//	 |
+//	 +--- &Item{Title: "Foo", ID: "foo", Items: ...}
+//	 |     |
+//	 |     +--- &Item{Title: "Bar", ID: "bar"}
+//	 |
+//	 +--- &Item{Title: "Baz", ID: "baz"}
+//
+// Whereas, by default, all items will be nested under "Title".
+//
+// Documents with more than one heading at the top level,
+// or whose first heading is not at the top level,
+// don't have a title and are not affected.
+func OmitLeadingTitle(omit bool) InspectOption {
+	return omitLeadingTitleOption(omit)
+}
+
+type omitLeadingTitleOption bool
+
+func (o omitLeadingTitleOption) apply(opts *inspectOptions) {
+	opts.omitLeadingTitle = bool(o)
+}
+
+func (o omitLeadingTitleOption) String() string {
+	return fmt.Sprintf("OmitLeadingTitle(%v)", bool(o))
+}
+
+// onLeadingTitleOption reports the leading title of the document.
+// This is used by the Transformer to place the TOC after it.
+type onLeadingTitleOption func(*ast.Heading)
+
+func (o onLeadingTitleOption) apply(opts *inspectOptions) {
+	opts.onLeadingTitle = o
+}
+
+// sectionOption limits the table of contents
+// to the headings under the given heading.
+// This is used by the Transformer for chapter tables of contents.
+type sectionOption struct{ heading *ast.Heading }
+
+func (o sectionOption) apply(opts *inspectOptions) {
+	opts.section = o.heading
+}
+
+// onItemOption reports items and the headings they were built from.
+// This is used by the Transformer to modify headings.
+type onItemOption func(*Item, *ast.Heading)
+
+func (o onItemOption) apply(opts *inspectOptions) {
+	opts.onItem = o
+}
+
 // Inspect builds a table of contents by inspecting the provided document.
 //
 // The table of contents is represents as a tree where each item represents a
@@ -204,9 +740,25 @@ func (c compactOption) String() string {
 //	 |
 //	 +--- &Item{Title: "Section 3", ID: "section-3"}
 //
+// Headings may be left out of the table of contents
+// with an HTML comment on the line before them:
+//
+//	<!-- toc:ignore -->
+//	## License
+//
+// Or with a class or attribute
+// if the parser was configured with parser.WithHeadingAttribute.
+// See IgnoreComment, IgnoreClass, and IgnoreAttribute for more information.
+// Headings nested under an ignored heading are left out as well.
+//
 // You may analyze or manipulate the table of contents before rendering it.
 func Inspect(n ast.Node, src []byte, options ...InspectOption) (*TOC, error) {
-	var opts inspectOptions
+	opts := inspectOptions{
+		ignoreClass:     _defaultIgnoreClass,
+		ignoreAttribute: _defaultIgnoreAttribute,
+		ignoreComment:   _defaultIgnoreComment,
+		labelAttribute:  _defaultLabelAttribute,
+	}
 	for _, opt := range options {
 		opt.apply(&opts)
 	}
@@ -228,37 +780,123 @@ func Inspect(n ast.Node, src []byte, options ...InspectOption) (*TOC, error) {
 		return appendChild(n)
 	}
 
-	var root Item
-
-	stack := []*Item{&root} // inv: len(stack) >= 1
+	// Collect headings in document order.
+	var headings []*ast.Heading
+	top := n
 	err := ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
 		}
 
-		heading, ok := n.(*ast.Heading)
-		if !ok {
-			return ast.WalkContinue, nil
-		}
-		if opts.minDepth > 0 && heading.Level < opts.minDepth {
+		switch n := n.(type) {
+		case *ast.Heading:
+			headings = append(headings, n)
+			return ast.WalkSkipChildren, nil
+
+		case *ast.HTMLBlock, *ast.Paragraph:
+			if opts.htmlHeadings {
+				headings = append(headings, htmlHeadings(src, n)...)
+			}
 			return ast.WalkSkipChildren, nil
 		}
 
-		if opts.maxDepth > 0 && heading.Level > opts.maxDepth {
+		if n != top && opts.excluded(n) {
 			return ast.WalkSkipChildren, nil
 		}
+		return ast.WalkContinue, nil
+	})
+
+	headings = opts.dropIgnored(src, headings)
+	if opts.section != nil {
+		headings = sectionHeadings(headings, opts.section)
+	}
+
+	// Headings left out by filters.
+	// These don't count when deciding the levels of other headings,
+	// but headings nested under them are still kept apart
+	// from the item before them.
+	filtered := make(map[*ast.Heading]struct{})
+	for _, h := range headings {
+		if !opts.keep(src, h) {
+			filtered[h] = struct{}{}
+		}
+	}
+	unfiltered := func(headings []*ast.Heading) []*ast.Heading {
+		return slices.DeleteFunc(slices.Clone(headings), func(h *ast.Heading) bool {
+			_, ok := filtered[h]
+			return ok
+		})
+	}
+
+	// Levels of headings are reduced by this much
+	// so that they start at level 1.
+	var shift int
+	if title := leadingTitle(headings, filtered); title != nil {
+		if opts.onLeadingTitle != nil {
+			opts.onLeadingTitle(title)
+		}
+		if opts.omitLeadingTitle {
+			headings = headings[1:]
+			shift = title.Level
+		}
+	}
+
+	headings = dropLevels(headings, opts.minDepth, opts.maxDepth)
+	if kept := unfiltered(headings); len(kept) > 0 {
+		base := shallowestLevel(kept)
+		headings = dropLevels(headings,
+			relativeDepth(base, opts.relativeMinDepth),
+			relativeDepth(base, opts.relativeMaxDepth))
+	}
+
+	// The shallowest heading must be at level 1.
+	// This is always deeper than the omitted title.
+	if kept := unfiltered(headings); opts.normalize && len(kept) > 0 {
+		shift = shallowestLevel(kept) - 1
+	}
+
+	var root Item
+
+	// If non-zero, a heading at this level was filtered out.
+	// Headings nested under it must not be attached
+	// to the item before it, so the next item at this level
+	// must be a new one.
+	var filteredLevel int
+
+	lines := lineIndex{src: src}
+	stack := []*Item{&root} // inv: len(stack) >= 1
+	for _, heading := range headings {
+		level := heading.Level - shift
+
+		if _, ok := filtered[heading]; ok {
+			// Filtered headings may be shallower
+			// than the headings that set the shift.
+			level = max(level, 1)
+			if len(stack) > level {
+				stack = stack[:level]
+			}
+			if filteredLevel == 0 || level < filteredLevel {
+				filteredLevel = level
+			}
+			continue
+		}
 
 		// The heading is deeper than the current depth.
 		// Append empty items to match the heading's level.
-		for len(stack) < heading.Level {
+		for len(stack) < level {
 			parent := stack[len(stack)-1]
-			stack = append(stack, lastChild(parent))
+			if len(stack) == filteredLevel {
+				stack = append(stack, appendChild(parent))
+			} else {
+				stack = append(stack, lastChild(parent))
+			}
 		}
+		filteredLevel = 0
 
 		// The heading is shallower than the current depth.
 		// Move back up the stack until we reach the heading's level.
-		if len(stack) > heading.Level {
-			stack = stack[:heading.Level]
+		if len(stack) > level {
+			stack = stack[:level]
 		}
 
 		parent := stack[len(stack)-1]
@@ -267,21 +905,251 @@ func Inspect(n ast.Node, src []byte, options ...InspectOption) (*TOC, error) {
 			target = appendChild(parent)
 		}
 
-		target.Title = util.UnescapePunctuations(nodeText(src, heading))
-		if id, ok := n.AttributeString("id"); ok {
+		title, changed := opts.title(src, heading)
+		target.Title = title
+		if id, ok := heading.AttributeString("id"); ok {
 			target.ID, _ = id.([]byte)
 		}
+		if opts.richTitles && !changed {
+			target.RichTitle = richTitle(src, heading, opts.textExtractors)
+		}
+		if opts.metadata {
+			target.Level = heading.Level
+			target.Line = lines.line(heading.Pos())
+			target.Heading = heading
+		}
+		if opts.onItem != nil {
+			opts.onItem(target, heading)
+		}
+	}
 
-		return ast.WalkSkipChildren, nil
-	})
+	if opts.metadata {
+		markPlaceholders(root.Items, shift+1)
+	}
 
 	if opts.compact {
 		compactItems(&root.Items)
 	}
 
+	if opts.numbered {
+		numberItems(root.Items, nil)
+	}
+
 	return &TOC{Items: root.Items}, err
 }
 
+// title returns the title of the item for the given heading.
+// changed reports whether this is different from the text of the heading
+// because of a label or a title function.
+func (o *inspectOptions) title(src []byte, h *ast.Heading) (title []byte, changed bool) {
+	title = util.UnescapePunctuations(o.textExtractors.text(src, h))
+
+	if name := o.labelAttribute; len(name) > 0 {
+		v, _ := h.AttributeString(name)
+		switch v := v.(type) {
+		case []byte:
+			title, changed = v, true
+		case string:
+			title, changed = []byte(v), true
+		}
+	}
+
+	for _, fn := range o.titleFuncs {
+		newTitle := fn(h, title)
+		changed = changed || !bytes.Equal(newTitle, title)
+		title = newTitle
+	}
+
+	return title, changed
+}
+
+// dropIgnored returns the given headings
+// without the ones that should be left out of the table of contents,
+// and the headings nested under them.
+func (o *inspectOptions) dropIgnored(src []byte, headings []*ast.Heading) []*ast.Heading {
+	// If non-zero, this is the level of the last ignored heading.
+	// Headings deeper than this are part of its section
+	// and are ignored too.
+	var ignoreLevel int
+
+	return slices.DeleteFunc(headings, func(h *ast.Heading) bool {
+		if ignoreLevel > 0 {
+			if h.Level > ignoreLevel {
+				return true
+			}
+			ignoreLevel = 0
+		}
+		if o.ignored(src, h) {
+			ignoreLevel = h.Level
+			return true
+		}
+		return false
+	})
+}
+
+// dropLevels returns the given headings without the ones
+// with levels outside [minDepth, maxDepth].
+// A value of 0 or less for either limit means no limit.
+func dropLevels(headings []*ast.Heading, minDepth, maxDepth int) []*ast.Heading {
+	return slices.DeleteFunc(headings, func(h *ast.Heading) bool {
+		return (minDepth > 0 && h.Level < minDepth) ||
+			(maxDepth > 0 && h.Level > maxDepth)
+	})
+}
+
+// relativeDepth returns the absolute heading level
+// for a depth relative to the given base level.
+// It returns 0 if depth is 0 or less.
+func relativeDepth(base, depth int) int {
+	if depth <= 0 {
+		return 0
+	}
+	return base + depth - 1
+}
+
+// sectionHeadings returns the headings in the section
+// started by the given heading, not including the heading itself.
+// It returns nil if the heading is not in the list.
+func sectionHeadings(headings []*ast.Heading, section *ast.Heading) []*ast.Heading {
+	start := slices.Index(headings, section)
+	if start < 0 {
+		return nil
+	}
+
+	headings = headings[start+1:]
+	for i, h := range headings {
+		if h.Level <= section.Level {
+			return headings[:i]
+		}
+	}
+	return headings
+}
+
+// leadingTitle returns the first of the given headings
+// if all other headings are deeper than it,
+// or nil otherwise.
+// Filtered headings other than the first are not compared.
+func leadingTitle(headings []*ast.Heading, filtered map[*ast.Heading]struct{}) *ast.Heading {
+	if len(headings) == 0 {
+		return nil
+	}
+
+	title := headings[0]
+	for _, h := range headings[1:] {
+		if _, ok := filtered[h]; ok {
+			continue
+		}
+		if h.Level <= title.Level {
+			return nil
+		}
+	}
+	return title
+}
+
+// shallowestLevel returns the lowest level of the given headings.
+// There must be at least one heading.
+func shallowestLevel(headings []*ast.Heading) int {
+	level := headings[0].Level
+	for _, h := range headings[1:] {
+		level = min(level, h.Level)
+	}
+	return level
+}
+
+// excluded reports whether headings inside the given node
+// should be left out of the table of contents.
+func (o *inspectOptions) excluded(n ast.Node) bool {
+	if o.topLevelOnly {
+		return true
+	}
+	_, ok := o.excludeContainers[n.Kind()]
+	return ok
+}
+
+// keep reports whether the given heading satisfies all filters.
+func (o *inspectOptions) keep(src []byte, h *ast.Heading) bool {
+	for _, f := range o.filters {
+		if !f(h, src) {
+			return false
+		}
+	}
+	return true
+}
+
+// ignored reports whether the given heading
+// should be left out of the table of contents.
+func (o *inspectOptions) ignored(src []byte, h *ast.Heading) bool {
+	if class := o.ignoreClass; len(class) > 0 {
+		if v, ok := h.AttributeString("class"); ok {
+			if classes, ok := v.([]byte); ok && hasClass(classes, class) {
+				return true
+			}
+		}
+	}
+
+	if name := o.ignoreAttribute; len(name) > 0 {
+		v, _ := h.AttributeString(name)
+		switch v := v.(type) {
+		case bool:
+			if !v {
+				return true
+			}
+		case []byte:
+			if string(v) == "false" {
+				return true
+			}
+		}
+	}
+
+	if text := o.ignoreComment; len(text) > 0 {
+		return hasIgnoreComment(src, h, text)
+	}
+	return false
+}
+
+func hasClass(classes []byte, class string) bool {
+	for _, c := range bytes.Fields(classes) {
+		if string(c) == class {
+			return true
+		}
+	}
+	return false
+}
+
+// hasIgnoreComment reports whether the given heading is
+// on the line right after an HTML comment with the given text,
+// e.g. <!-- toc:ignore -->.
+func hasIgnoreComment(src []byte, h *ast.Heading, text string) bool {
+	block, ok := h.PreviousSibling().(*ast.HTMLBlock)
+	if !ok || block.Lines().Len() == 0 {
+		return false
+	}
+
+	// The comment must end on the line before the heading.
+	last := block.Lines().At(block.Lines().Len() - 1)
+	if block.HasClosure() {
+		last = block.ClosureLine
+	}
+	pos := h.Pos()
+	if pos < 0 && h.Lines().Len() > 0 {
+		pos = h.Lines().At(0).Start
+	}
+	if pos >= 0 && bytes.Count(src[last.Start:pos], []byte("\n")) > 1 {
+		return false
+	}
+
+	comment := block.Lines().Value(src)
+	if block.HasClosure() {
+		comment = append(comment, block.ClosureLine.Value(src)...)
+	}
+	comment, ok = bytes.CutPrefix(bytes.TrimSpace(comment), []byte("<!--"))
+	if !ok {
+		return false
+	}
+	comment, ok = bytes.CutSuffix(comment, []byte("-->"))
+	return ok && string(bytes.TrimSpace(comment)) == text
+}
+
 // compactItems removes items with no titles
 // from the given list of items.
 //
@@ -304,21 +1172,42 @@ func compactItems(items *Items) {
 	}
 }
 
-func nodeText(src []byte, n ast.Node) []byte {
-	var buf bytes.Buffer
-	writeNodeText(src, &buf, n)
-	return buf.Bytes()
+// markPlaceholders marks items that weren't built from a heading
+// as placeholders, and records the levels they stand for.
+// level is the heading level of the given items.
+//
+// This must be called before the items are compacted
+// because compaction changes the depth of items.
+func markPlaceholders(items Items, level int) {
+	for _, item := range items {
+		if item.Heading == nil {
+			item.Placeholder = true
+			item.Level = level
+		}
+		markPlaceholders(item.Items, level+1)
+	}
 }
 
-func writeNodeText(src []byte, dst io.Writer, n ast.Node) {
-	switch n := n.(type) {
-	case *ast.Text:
-		_, _ = dst.Write(n.Segment.Value(src))
-	case *ast.String:
-		_, _ = dst.Write(n.Value)
-	default:
-		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
-			writeNodeText(src, dst, c)
-		}
+// lineIndex finds line numbers of offsets in a source.
+// It's fastest when offsets are requested in increasing order.
+type lineIndex struct {
+	src []byte
+
+	off   int // last offset requested
+	count int // number of newlines before off
+}
+
+// line returns the 1-indexed line number of the given offset,
+// or 0 if the offset is not in the source.
+func (l *lineIndex) line(off int) int {
+	if off < 0 || off > len(l.src) {
+		return 0
+	}
+
+	if off < l.off {
+		l.off, l.count = 0, 0
 	}
+	l.count += bytes.Count(l.src[l.off:off], []byte{'\n'})
+	l.off = off
+	return l.count + 1
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 	"pgregory.net/rapid"
@@ -287,6 +288,1127 @@ func TestInspect(t *testing.T) {
 	}
 }
 
+func TestInspectIgnore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give []string // lines of a doc
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "class",
+			give: []string{
+				"# Foo",
+				"## Bar {.notoc}",
+				"## Baz",
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Baz", "baz")),
+			},
+		},
+		{
+			desc: "class among others",
+			give: []string{
+				"# Foo",
+				"## Bar {.wide .notoc #bar}",
+			},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "attribute",
+			give: []string{
+				"# Foo",
+				`## Bar {data-toc="false"}`,
+				"## Baz {data-toc=false}",
+				`## Qux {data-toc="true"}`,
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Qux", "qux")),
+			},
+		},
+		{
+			desc: "comment",
+			give: []string{
+				"# Foo",
+				"",
+				"<!-- toc:ignore -->",
+				"## Bar",
+				"",
+				"<!--toc:ignore-->",
+				"## Baz",
+				"",
+				"<!-- toc -->",
+				"## Qux",
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Qux", "qux")),
+			},
+		},
+		{
+			desc: "comment not adjacent",
+			give: []string{
+				"<!-- toc:ignore -->",
+				"",
+				"Paragraph.",
+				"",
+				"# Foo",
+			},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "comment before blank line",
+			give: []string{
+				"<!-- toc:ignore -->",
+				"",
+				"# Foo",
+				"",
+				"> <!-- toc:ignore -->",
+				">",
+				"> # Bar",
+			},
+			want: Items{
+				item("Foo", "foo"),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "comment in block quote",
+			give: []string{
+				"# Foo",
+				"",
+				"> <!-- toc:ignore -->",
+				"> # Bar",
+			},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "multi-line comment",
+			give: []string{
+				"<!--",
+				"toc:ignore",
+				"-->",
+				"# Foo",
+				"# Bar",
+			},
+			want: Items{
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "nested headings",
+			give: []string{
+				"# Foo",
+				"## License {.notoc}",
+				"### MIT",
+				"#### Text",
+				"## Changelog",
+				"### v1.0",
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Changelog", "changelog",
+						item("v1.0", "v10"))),
+			},
+		},
+		{
+			desc: "no placeholders",
+			give: []string{
+				"# Foo {.notoc}",
+				"## Bar",
+				"# Baz",
+				"### Qux {.notoc}",
+				"## Quux",
+			},
+			want: Items{
+				item("Baz", "baz",
+					item("Quux", "quux")),
+			},
+		},
+		{
+			desc: "custom class",
+			give: []string{
+				"# Foo {.notoc}",
+				"# Bar {.hidden}",
+			},
+			opts: []InspectOption{IgnoreClass("hidden")},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "custom attribute",
+			give: []string{
+				`# Foo {data-toc="false"}`,
+				`# Bar {toc="false"}`,
+			},
+			opts: []InspectOption{IgnoreAttribute("toc")},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "disabled",
+			give: []string{
+				`# Foo {.notoc}`,
+				`# Bar {data-toc="false"}`,
+			},
+			opts: []InspectOption{IgnoreClass(""), IgnoreAttribute("")},
+			want: Items{
+				item("Foo", "foo"),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "custom comment",
+			give: []string{
+				"<!-- toc:ignore -->",
+				"# Foo",
+				"<!-- skip -->",
+				"# Bar",
+			},
+			opts: []InspectOption{IgnoreComment("skip")},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "comment disabled",
+			give: []string{
+				"<!-- toc:ignore -->",
+				"# Foo",
+			},
+			opts: []InspectOption{IgnoreComment("")},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "below minDepth",
+			give: []string{
+				"# Foo {.notoc}",
+				"## Bar",
+				"# Baz",
+				"## Qux",
+			},
+			opts: []InspectOption{MinDepth(2)},
+			want: Items{
+				item("", "",
+					item("Qux", "qux")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+				parser.WithHeadingAttribute(),
+			).Parse(text.NewReader(src))
+
+			got, err := Inspect(doc, src, tt.opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestInspectFilter(t *testing.T) {
+	t.Parallel()
+
+	notTitled := func(title string) func(*ast.Heading, []byte) bool {
+		return func(h *ast.Heading, src []byte) bool {
+			return string(nodeText(src, h)) != title
+		}
+	}
+
+	tests := []struct {
+		desc string
+		give []string // lines of a doc
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "sibling",
+			give: []string{
+				"# Foo",
+				"## Bar",
+				"## Baz",
+			},
+			opts: []InspectOption{Filter(notTitled("Bar"))},
+			want: Items{
+				item("Foo", "foo",
+					item("Baz", "baz")),
+			},
+		},
+		{
+			desc: "nested headings",
+			give: []string{
+				"# Foo",
+				"## Bar",
+				"## Baz",
+				"### Qux",
+				"#### Quux",
+				"## Corge",
+			},
+			opts: []InspectOption{Filter(notTitled("Baz"))},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar"),
+					item("", "",
+						item("Qux", "qux",
+							item("Quux", "quux"))),
+					item("Corge", "corge")),
+			},
+		},
+		{
+			desc: "nested headings/compact",
+			give: []string{
+				"# Foo",
+				"## Bar",
+				"## Baz",
+				"### Qux",
+				"## Corge",
+			},
+			opts: []InspectOption{Filter(notTitled("Baz")), Compact(true)},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar"),
+					item("Qux", "qux"),
+					item("Corge", "corge")),
+			},
+		},
+		{
+			desc: "deeper than previous",
+			give: []string{
+				"# Foo",
+				"## Bar",
+				"### Baz",
+				"### Qux",
+				"#### Quux",
+			},
+			opts: []InspectOption{Filter(notTitled("Qux"))},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar",
+						item("Baz", "baz"),
+						item("", "",
+							item("Quux", "quux")))),
+			},
+		},
+		{
+			desc: "consecutive",
+			give: []string{
+				"# Foo",
+				"## Bar",
+				"## Baz",
+				"### Qux",
+				"#### Quux",
+			},
+			opts: []InspectOption{
+				Filter(notTitled("Baz")),
+				Filter(notTitled("Qux")),
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar"),
+					item("", "",
+						item("", "",
+							item("Quux", "quux")))),
+			},
+		},
+		{
+			desc: "block quote",
+			give: []string{
+				"# Foo",
+				"> ## Bar",
+				"## Baz",
+			},
+			opts: []InspectOption{
+				Filter(func(h *ast.Heading, _ []byte) bool {
+					return h.Parent().Kind() != ast.KindBlockquote
+				}),
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Baz", "baz")),
+			},
+		},
+		{
+			desc: "nil",
+			give: []string{
+				"# Foo",
+			},
+			opts: []InspectOption{Filter(nil)},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src))
+
+			got, err := Inspect(doc, src, tt.opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestInspectRelativeDepth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give []string // lines of a doc
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "relative max depth",
+			give: []string{
+				"## Foo",
+				"### Bar",
+				"#### Baz",
+				"## Qux",
+			},
+			opts: []InspectOption{RelativeMaxDepth(2)},
+			want: Items{
+				item("", "",
+					item("Foo", "foo",
+						item("Bar", "bar")),
+					item("Qux", "qux")),
+			},
+		},
+		{
+			desc: "relative min depth",
+			give: []string{
+				"## Foo",
+				"### Bar",
+				"#### Baz",
+				"## Qux",
+			},
+			opts: []InspectOption{RelativeMinDepth(2), Compact(true)},
+			want: Items{
+				item("Bar", "bar",
+					item("Baz", "baz")),
+			},
+		},
+		{
+			desc: "relative to absolute limits",
+			give: []string{
+				"# Title",
+				"## Foo",
+				"### Bar",
+				"#### Baz",
+			},
+			opts: []InspectOption{MinDepth(2), RelativeMaxDepth(2), Compact(true)},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+			},
+		},
+		{
+			desc: "stricter limit wins",
+			give: []string{
+				"## Foo",
+				"### Bar",
+				"#### Baz",
+			},
+			opts: []InspectOption{MaxDepth(2), RelativeMaxDepth(3), Compact(true)},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "ignored headings",
+			give: []string{
+				"## Foo",
+				"### Bar",
+				"#### Baz",
+				"<!-- toc:ignore -->",
+				"# Appendix",
+			},
+			opts: []InspectOption{RelativeMaxDepth(2), Compact(true)},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+			},
+		},
+		{
+			desc: "normalize",
+			give: []string{
+				"## Foo",
+				"#### Bar",
+				"## Baz",
+			},
+			opts: []InspectOption{Normalize(true)},
+			want: Items{
+				item("Foo", "foo",
+					item("", "",
+						item("Bar", "bar"))),
+				item("Baz", "baz"),
+			},
+		},
+		{
+			desc: "normalize after limits",
+			give: []string{
+				"# Title",
+				"### Foo",
+				"#### Bar",
+			},
+			opts: []InspectOption{MinDepth(2), Normalize(true)},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+			},
+		},
+		{
+			desc: "normalize with filter",
+			give: []string{
+				"## Foo",
+				"### Bar",
+			},
+			opts: []InspectOption{
+				Normalize(true),
+				Filter(func(h *ast.Heading, _ []byte) bool {
+					return h.Level != 2
+				}),
+			},
+			want: Items{
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "normalize with filtered title",
+			give: []string{
+				"# Title",
+				"## Foo",
+				"## Bar",
+			},
+			opts: []InspectOption{
+				Normalize(true),
+				Filter(func(h *ast.Heading, _ []byte) bool {
+					return h.Level != 1
+				}),
+			},
+			want: Items{
+				item("Foo", "foo"),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "relative max depth with filtered title",
+			give: []string{
+				"# Title",
+				"## Foo",
+				"### Bar",
+				"#### Baz",
+			},
+			opts: []InspectOption{
+				RelativeMaxDepth(2),
+				Filter(func(h *ast.Heading, _ []byte) bool {
+					return h.Level != 1
+				}),
+			},
+			want: Items{
+				item("", "",
+					item("Foo", "foo",
+						item("Bar", "bar"))),
+			},
+		},
+		{
+			desc: "empty document",
+			opts: []InspectOption{RelativeMaxDepth(2), Normalize(true)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src))
+
+			got, err := Inspect(doc, src, tt.opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestInspectOmitLeadingTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give []string // lines of a doc
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "title",
+			give: []string{
+				"# Title",
+				"## Foo",
+				"### Bar",
+				"## Baz",
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+				item("Baz", "baz"),
+			},
+		},
+		{
+			desc: "title at level 2",
+			give: []string{
+				"## Title",
+				"### Foo",
+				"### Bar",
+			},
+			want: Items{
+				item("Foo", "foo"),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "only title",
+			give: []string{
+				"# Title",
+			},
+		},
+		{
+			desc: "gap under title",
+			give: []string{
+				"# Title",
+				"### Foo",
+				"## Bar",
+			},
+			want: Items{
+				item("", "",
+					item("Foo", "foo")),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "gap under title/normalize",
+			give: []string{
+				"# Title",
+				"### Foo",
+				"### Bar",
+			},
+			opts: []InspectOption{Normalize(true)},
+			want: Items{
+				item("Foo", "foo"),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "multiple top-level headings",
+			give: []string{
+				"# Foo",
+				"## Bar",
+				"# Baz",
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+				item("Baz", "baz"),
+			},
+		},
+		{
+			desc: "first heading not top-level",
+			give: []string{
+				"## Foo",
+				"# Bar",
+			},
+			want: Items{
+				item("", "",
+					item("Foo", "foo")),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "ignored heading",
+			give: []string{
+				"# Title",
+				"## Foo",
+				"<!-- toc:ignore -->",
+				"# License",
+			},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "filtered heading",
+			give: []string{
+				"# Title",
+				"## Foo",
+				"# Appendix",
+			},
+			opts: []InspectOption{
+				Filter(func(h *ast.Heading, src []byte) bool {
+					return string(h.Lines().Value(src)) != "Appendix"
+				}),
+			},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "relative max depth",
+			give: []string{
+				"# Title",
+				"## Foo",
+				"### Bar",
+				"#### Baz",
+			},
+			opts: []InspectOption{RelativeMaxDepth(2)},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+			},
+		},
+		{
+			desc: "disabled",
+			give: []string{
+				"# Title",
+				"## Foo",
+			},
+			opts: []InspectOption{OmitLeadingTitle(false)},
+			want: Items{
+				item("Title", "title",
+					item("Foo", "foo")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src))
+
+			opts := append([]InspectOption{OmitLeadingTitle(true)}, tt.opts...)
+			got, err := Inspect(doc, src, opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestInspectMetadata(t *testing.T) {
+	t.Parallel()
+
+	// meta sets metadata on an item built from a heading.
+	meta := func(it *Item, level, line int) *Item {
+		it.Level = level
+		it.Line = line
+		return it
+	}
+
+	// placeholder builds a placeholder item.
+	placeholder := func(level int, items ...*Item) *Item {
+		return &Item{Level: level, Placeholder: true, Items: items}
+	}
+
+	tests := []struct {
+		desc string
+		give []string // lines of a doc
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "levels",
+			give: []string{
+				"# Foo",
+				"",
+				"## Bar",
+				"",
+				"# Baz",
+			},
+			want: Items{
+				meta(item("Foo", "foo",
+					meta(item("Bar", "bar"), 2, 3)), 1, 1),
+				meta(item("Baz", "baz"), 1, 5),
+			},
+		},
+		{
+			desc: "placeholders",
+			give: []string{
+				"# Foo",
+				"#### Bar",
+				"## Baz",
+			},
+			want: Items{
+				meta(item("Foo", "foo",
+					placeholder(2,
+						placeholder(3,
+							meta(item("Bar", "bar"), 4, 2))),
+					meta(item("Baz", "baz"), 2, 3)), 1, 1),
+			},
+		},
+		{
+			desc: "setext",
+			give: []string{
+				"Intro",
+				"",
+				"Foo",
+				"===",
+			},
+			want: Items{
+				meta(item("Foo", "foo"), 1, 3),
+			},
+		},
+		{
+			desc: "normalize",
+			give: []string{
+				"### Foo",
+				"##### Bar",
+			},
+			opts: []InspectOption{Normalize(true)},
+			want: Items{
+				meta(item("Foo", "foo",
+					placeholder(4,
+						meta(item("Bar", "bar"), 5, 2))), 3, 1),
+			},
+		},
+		{
+			desc: "compact",
+			give: []string{
+				"# Foo",
+				"### Bar",
+			},
+			opts: []InspectOption{Compact(true)},
+			want: Items{
+				meta(item("Foo", "foo",
+					meta(item("Bar", "bar"), 3, 2)), 1, 1),
+			},
+		},
+		{
+			desc: "html heading",
+			give: []string{
+				"# Foo",
+				"",
+				"<div>",
+				"<h2 id=\"bar\">Bar</h2>",
+				"</div>",
+			},
+			opts: []InspectOption{HTMLHeadings(true)},
+			want: Items{
+				meta(item("Foo", "foo",
+					meta(item("Bar", "bar"), 2, 4)), 1, 1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src))
+
+			opts := append([]InspectOption{Metadata(true)}, tt.opts...)
+			got, err := Inspect(doc, src, opts...)
+			require.NoError(t, err, "inspect error")
+
+			// Headings can't be compared directly,
+			// so verify and clear them.
+			var check func(Items)
+			check = func(items Items) {
+				for _, it := range items {
+					if it.Placeholder {
+						assert.Nil(t, it.Heading, "placeholder must not have a heading")
+					} else if assert.NotNil(t, it.Heading, "%q must have a heading", it.Title) {
+						assert.Equal(t, it.Level, it.Heading.Level, "level of %q", it.Title)
+						it.Heading = nil
+					}
+					check(it.Items)
+				}
+			}
+			check(got.Items)
+
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestInspectContainers(t *testing.T) {
+	t.Parallel()
+
+	give := []string{
+		"# Foo",
+		"",
+		"> ## Bar",
+		"",
+		"- ## Baz",
+		"",
+		"  > ### Qux",
+		"",
+		"## Quux",
+	}
+
+	tests := []struct {
+		desc string
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "default",
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar"),
+					item("Baz", "baz",
+						item("Qux", "qux")),
+					item("Quux", "quux")),
+			},
+		},
+		{
+			desc: "top level only",
+			opts: []InspectOption{TopLevelOnly(true)},
+			want: Items{
+				item("Foo", "foo",
+					item("Quux", "quux")),
+			},
+		},
+		{
+			desc: "exclude blockquote",
+			opts: []InspectOption{ExcludeContainers(ast.KindBlockquote)},
+			want: Items{
+				item("Foo", "foo",
+					item("Baz", "baz"),
+					item("Quux", "quux")),
+			},
+		},
+		{
+			desc: "exclude list",
+			opts: []InspectOption{ExcludeContainers(ast.KindList)},
+			want: Items{
+				item("Foo", "foo",
+					item("Bar", "bar"),
+					item("Quux", "quux")),
+			},
+		},
+		{
+			desc: "multiple options",
+			opts: []InspectOption{
+				ExcludeContainers(ast.KindBlockquote),
+				ExcludeContainers(ast.KindListItem),
+			},
+			want: Items{
+				item("Foo", "foo",
+					item("Quux", "quux")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src))
+
+			got, err := Inspect(doc, src, tt.opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+var kindTestContainer = ast.NewNodeKind("TestContainer")
+
+// testContainer is a block container from an extension.
+type testContainer struct{ ast.BaseBlock }
+
+func (*testContainer) Kind() ast.NodeKind { return kindTestContainer }
+
+func (c *testContainer) Dump(src []byte, level int) {
+	ast.DumpHelper(c, src, level, nil, nil)
+}
+
+func TestInspectContainers_extension(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("# Foo\n\n> ## Bar\n")
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithAutoHeadingID(),
+	).Parse(text.NewReader(src))
+
+	// Replace the block quote with a container of a custom kind.
+	quote := doc.LastChild()
+	require.Equal(t, ast.KindBlockquote, quote.Kind())
+	container := new(testContainer)
+	for c := quote.FirstChild(); c != nil; c = quote.FirstChild() {
+		container.AppendChild(container, c)
+	}
+	doc.ReplaceChild(doc, quote, container)
+
+	got, err := Inspect(doc, src)
+	require.NoError(t, err, "inspect error")
+	assert.Equal(t, &TOC{Items: Items{item("Foo", "foo", item("Bar", "bar"))}}, got)
+
+	got, err = Inspect(doc, src, ExcludeContainers(kindTestContainer))
+	require.NoError(t, err, "inspect error")
+	assert.Equal(t, &TOC{Items: Items{item("Foo", "foo")}}, got)
+}
+
+func TestInspectNumbered(t *testing.T) {
+	t.Parallel()
+
+	numbered := func(it *Item, number ...int) *Item {
+		it.Number = number
+		return it
+	}
+
+	tests := []struct {
+		desc string
+		give []string // lines of a doc
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "simple",
+			give: []string{
+				"# Foo",
+				"## Bar",
+				"## Baz",
+				"### Qux",
+				"# Quux",
+			},
+			want: Items{
+				numbered(item("Foo", "foo",
+					numbered(item("Bar", "bar"), 1, 1),
+					numbered(item("Baz", "baz",
+						numbered(item("Qux", "qux"), 1, 2, 1),
+					), 1, 2),
+				), 1),
+				numbered(item("Quux", "quux"), 2),
+			},
+		},
+		{
+			desc: "empty items",
+			give: []string{
+				"# Foo",
+				"### Bar",
+				"#### Baz",
+				"## Qux",
+			},
+			want: Items{
+				numbered(item("Foo", "foo",
+					item("", "",
+						numbered(item("Bar", "bar",
+							numbered(item("Baz", "baz"), 1, 1, 1),
+						), 1, 1),
+					),
+					numbered(item("Qux", "qux"), 1, 2),
+				), 1),
+			},
+		},
+		{
+			desc: "empty root",
+			give: []string{
+				"## Foo",
+				"## Bar",
+				"# Baz",
+			},
+			want: Items{
+				item("", "",
+					numbered(item("Foo", "foo"), 1),
+					numbered(item("Bar", "bar"), 2),
+				),
+				numbered(item("Baz", "baz"), 3),
+			},
+		},
+		{
+			desc: "compact",
+			give: []string{
+				"# Foo",
+				"### Bar",
+				"## Baz",
+			},
+			opts: []InspectOption{Compact(true)},
+			want: Items{
+				numbered(item("Foo", "foo",
+					numbered(item("Bar", "bar"), 1, 1),
+					numbered(item("Baz", "baz"), 1, 2),
+				), 1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src))
+
+			opts := append([]InspectOption{Numbered(true)}, tt.opts...)
+			got, err := Inspect(doc, src, opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
 func TestInspectOption_String(t *testing.T) {
 	t.Parallel()
 
@@ -300,7 +1422,28 @@ func TestInspectOption_String(t *testing.T) {
 		{give: MaxDepth(3), want: "MaxDepth(3)"},
 		{give: MaxDepth(0), want: "MaxDepth(0)"},
 		{give: MaxDepth(-1), want: "MaxDepth(-1)"},
+		{give: RelativeMinDepth(2), want: "RelativeMinDepth(2)"},
+		{give: RelativeMaxDepth(0), want: "RelativeMaxDepth(0)"},
+		{give: Normalize(true), want: "Normalize(true)"},
 		{give: Compact(true), want: "Compact(true)"},
+		{give: IgnoreClass("notoc"), want: `IgnoreClass("notoc")`},
+		{give: IgnoreAttribute(""), want: `IgnoreAttribute("")`},
+		{give: IgnoreComment("toc:ignore"), want: `IgnoreComment("toc:ignore")`},
+		{give: Filter(nil), want: "Filter(...)"},
+		{give: Numbered(true), want: "Numbered(true)"},
+		{give: RichTitles(true), want: "RichTitles(true)"},
+		{give: Metadata(true), want: "Metadata(true)"},
+		{give: LabelAttribute("toc-label"), want: `LabelAttribute("toc-label")`},
+		{give: TitleFunc(nil), want: "TitleFunc(...)"},
+		{give: HTMLHeadings(true), want: "HTMLHeadings(true)"},
+		{give: OmitLeadingTitle(true), want: "OmitLeadingTitle(true)"},
+		{give: TopLevelOnly(true), want: "TopLevelOnly(true)"},
+		{give: ExcludeContainers(), want: "ExcludeContainers()"},
+		{
+			give: ExcludeContainers(ast.KindBlockquote, ast.KindListItem),
+			want: "ExcludeContainers(Blockquote, ListItem)",
+		},
+		{give: ExtractText(ast.KindRawHTML, nil), want: "ExtractText(RawHTML, ...)"},
 	}
 
 	for _, tt := range tests {
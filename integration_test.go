@@ -26,10 +26,36 @@ func TestIntegration(t *testing.T) {
 		TitleDepth int    `yaml:"titleDepth"`
 		ListID     string `yaml:"listID"`
 		TitleID    string `yaml:"titleID"`
+		BaseURL    string `yaml:"baseURL"`
 
 		MinDepth int  `yaml:"minDepth"`
 		MaxDepth int  `yaml:"maxDepth"`
 		Compact  bool `yaml:"compact"`
+		MinItems int  `yaml:"minItems"`
+
+		Marker   string       `yaml:"marker"`
+		Position toc.Position `yaml:"position"`
+
+		LeadingTitle toc.LeadingTitle `yaml:"leadingTitle"`
+
+		// Disabled makes Configure turn off the table of contents.
+		Disabled bool `yaml:"disabled"`
+
+		Nav      bool   `yaml:"nav"`
+		NavID    string `yaml:"navID"`
+		NavClass string `yaml:"navClass"`
+		NavLabel string `yaml:"navLabel"`
+
+		Numbered       bool `yaml:"numbered"`
+		NumberHeadings bool `yaml:"numberHeadings"`
+
+		RichTitles   bool `yaml:"richTitles"`
+		HTMLHeadings bool `yaml:"htmlHeadings"`
+
+		ChapterLevel    int    `yaml:"chapterLevel"`
+		ChapterTitle    string `yaml:"chapterTitle"`
+		ChapterMaxDepth int    `yaml:"chapterMaxDepth"`
+		ChapterMinItems int    `yaml:"chapterMinItems"`
 	}
 	require.NoError(t, yaml.Unmarshal(testsdata, &tests))
 
@@ -38,15 +64,40 @@ func TestIntegration(t *testing.T) {
 		t.Run(tt.Desc, func(t *testing.T) {
 			t.Parallel()
 
+			var configure func(parser.Context, *toc.Transformer) bool
+			if tt.Disabled {
+				configure = func(parser.Context, *toc.Transformer) bool {
+					return false
+				}
+			}
+
 			md := goldmark.New(
 				goldmark.WithExtensions(&toc.Extender{
-					Title:      tt.Title,
-					TitleDepth: tt.TitleDepth,
-					MinDepth:   tt.MinDepth,
-					MaxDepth:   tt.MaxDepth,
-					Compact:    tt.Compact,
-					ListID:     tt.ListID,
-					TitleID:    tt.TitleID,
+					Title:           tt.Title,
+					TitleDepth:      tt.TitleDepth,
+					MinDepth:        tt.MinDepth,
+					MaxDepth:        tt.MaxDepth,
+					Compact:         tt.Compact,
+					MinItems:        tt.MinItems,
+					ListID:          tt.ListID,
+					TitleID:         tt.TitleID,
+					BaseURL:         tt.BaseURL,
+					Marker:          tt.Marker,
+					Position:        tt.Position,
+					LeadingTitle:    tt.LeadingTitle,
+					Nav:             tt.Nav,
+					NavID:           tt.NavID,
+					NavClass:        tt.NavClass,
+					NavLabel:        tt.NavLabel,
+					Numbered:        tt.Numbered,
+					NumberHeadings:  tt.NumberHeadings,
+					RichTitles:      tt.RichTitles,
+					HTMLHeadings:    tt.HTMLHeadings,
+					ChapterLevel:    tt.ChapterLevel,
+					ChapterTitle:    tt.ChapterTitle,
+					ChapterMaxDepth: tt.ChapterMaxDepth,
+					ChapterMinItems: tt.ChapterMinItems,
+					Configure:       configure,
 				}),
 				goldmark.WithParserOptions(parser.WithAutoHeadingID()),
 			)
@@ -0,0 +1,338 @@
+package toc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// LinkStyle specifies how MarkdownRenderer writes links.
+type LinkStyle int
+
+const (
+	// LinkInline writes links inline:
+	//
+	//	- [Foo](#foo)
+	//
+	// This is the default.
+	LinkInline LinkStyle = iota
+
+	// LinkReference writes links as references,
+	// with their definitions after the list:
+	//
+	//	- [Foo][foo]
+	//
+	//	[foo]: #foo
+	LinkReference
+)
+
+const _defaultMarkdownMarker = '-'
+
+// MarkdownRenderer renders a table of contents as Markdown source text.
+//
+// For example,
+//
+//	# Foo
+//	## Bar
+//	## Baz
+//	# Qux
+//
+//	// becomes
+//
+//	- [Foo](#foo)
+//	  - [Bar](#bar)
+//	  - [Baz](#baz)
+//	- [Qux](#qux)
+//
+// Titles are escaped so that parsing the output as Markdown
+// reproduces the same titles and links.
+//
+// Use this to embed a table of contents in a Markdown document
+// instead of rendering it to HTML.
+type MarkdownRenderer struct {
+	// Marker for elements of the list.
+	// Use '-', '*', or '+' for bullet lists,
+	// and '.' or ')' for ordered lists.
+	//
+	// Defaults to '-'.
+	Marker byte
+
+	// Indent is the number of spaces that nested lists
+	// are indented by relative to their parent item.
+	//
+	// Markdown requires nested lists to be indented
+	// at least as far as the text of their parent item.
+	// For example, 2 spaces for "- " and 3 spaces for "1. ".
+	// Indent values outside that range are adjusted to fit it.
+	//
+	// Defaults to the width of the parent item's list marker.
+	Indent int
+
+	// LinkStyle specifies how links are written.
+	//
+	// Defaults to LinkInline.
+	LinkStyle LinkStyle
+
+	// NumberFormat formats section numbers of items
+	// that have them (see Numbered).
+	// The formatted number is placed before the title,
+	// separated by a space.
+	//
+	// Defaults to FormatNumber(Decimal), which renders "3.2.1".
+	NumberFormat NumberFormat
+
+	// BaseURL is placed before links to headings.
+	// See ListRenderer.BaseURL for more information.
+	BaseURL string
+
+	// LinkFunc, if set, decides the link destination for each item.
+	// See ListRenderer.LinkFunc for more information.
+	LinkFunc LinkFunc
+}
+
+// Render writes the table of contents as a Markdown list to w.
+//
+// Nothing is written if the TOC is nil or empty.
+func (r *MarkdownRenderer) Render(w io.Writer, toc *TOC) error {
+	if toc == nil || len(toc.Items) == 0 {
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	mw := markdownWriter{
+		MarkdownRenderer: r,
+		w:                bw,
+		seenRefs:         make(map[string]string),
+	}
+	mw.writeItems(toc.Items, 0, false)
+
+	if len(mw.refs) > 0 {
+		_ = bw.WriteByte('\n')
+		for _, ref := range mw.refs {
+			_, _ = fmt.Fprintf(bw, "[%s]: ", escapeMarkdown(ref.label, false))
+			writeDestination(bw, ref.dest)
+			_ = bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+type markdownWriter struct {
+	*MarkdownRenderer
+
+	w *bufio.Writer
+
+	// Link reference definitions to write after the list.
+	refs []markdownRef
+
+	// Normalized labels of link reference definitions
+	// mapped to the destinations they refer to.
+	seenRefs map[string]string
+}
+
+// markdownRef is a link reference definition.
+type markdownRef struct {
+	label []byte // ID of the item
+	dest  string
+}
+
+// writeItems writes the given items as a list
+// with each item indented by the given number of spaces.
+//
+// If inline is true, the first item is written on the current line
+// after the marker of its parent item.
+func (mw *markdownWriter) writeItems(items Items, indent int, inline bool) {
+	marker := mw.Marker
+	if marker == 0 {
+		marker = _defaultMarkdownMarker
+	}
+	ordered := marker == '.' || marker == ')'
+
+	for i, item := range items {
+		mkr := string(marker)
+		if ordered {
+			mkr = strconv.Itoa(i+1) + mkr
+		}
+		width := len(mkr) + 1
+
+		if i > 0 || !inline {
+			writeIndent(mw.w, indent)
+		}
+		_, _ = mw.w.WriteString(mkr)
+
+		switch {
+		case len(item.Title) > 0:
+			_ = mw.w.WriteByte(' ')
+			mw.writeTitle(item)
+			_ = mw.w.WriteByte('\n')
+			mw.writeItems(item.Items, indent+mw.indent(width), false)
+
+		case len(item.Items) > 0:
+			// An empty item followed by a nested list on the next line
+			// would be read as a setext heading underline
+			// if it follows the text of another item.
+			// Start the nested list on the same line instead.
+			_ = mw.w.WriteByte(' ')
+			mw.writeItems(item.Items, indent+width, true)
+
+		default:
+			_ = mw.w.WriteByte('\n')
+		}
+	}
+}
+
+// indent returns the number of spaces to indent nested lists by
+// for a parent item whose text starts at the given column.
+func (mw *markdownWriter) indent(width int) int {
+	indent := mw.Indent
+	if indent < width {
+		return width
+	}
+	// Lines indented four or more spaces past the parent's text
+	// are code blocks.
+	return min(indent, width+3)
+}
+
+func (mw *markdownWriter) writeTitle(item *Item) {
+	title := item.Title
+	if len(item.Number) > 0 {
+		format := mw.NumberFormat
+		if format == nil {
+			format = _defaultNumberFormat
+		}
+		title = append([]byte(format(item.Number)+" "), title...)
+	}
+
+	dest := string(itemLink(item, mw.BaseURL, mw.LinkFunc))
+	if len(dest) == 0 {
+		_, _ = mw.w.Write(escapeMarkdown(title, true))
+		return
+	}
+
+	_ = mw.w.WriteByte('[')
+	_, _ = mw.w.Write(escapeMarkdown(title, false))
+	_ = mw.w.WriteByte(']')
+
+	if mw.LinkStyle == LinkReference && mw.addRef(item.ID, dest) {
+		_ = mw.w.WriteByte('[')
+		_, _ = mw.w.Write(escapeMarkdown(item.ID, false))
+		_ = mw.w.WriteByte(']')
+		return
+	}
+
+	_ = mw.w.WriteByte('(')
+	writeDestination(mw.w, dest)
+	_ = mw.w.WriteByte(')')
+}
+
+// addRef records a link reference definition
+// from the given ID to the given destination.
+//
+// It returns false if the ID cannot be used as a reference label
+// because it's blank, or because it matches the label
+// of a different destination.
+func (mw *markdownWriter) addRef(id []byte, dest string) bool {
+	// Reference labels are matched case-insensitively,
+	// with consecutive whitespace collapsed.
+	key := string(bytes.ToLower(bytes.Join(bytes.Fields(id), []byte(" "))))
+	if len(key) == 0 {
+		return false
+	}
+
+	if seen, ok := mw.seenRefs[key]; ok {
+		return seen == dest
+	}
+
+	mw.seenRefs[key] = dest
+	mw.refs = append(mw.refs, markdownRef{label: id, dest: dest})
+	return true
+}
+
+func writeIndent(w *bufio.Writer, n int) {
+	for range n {
+		_ = w.WriteByte(' ')
+	}
+}
+
+// writeDestination writes a link destination,
+// wrapping it in <...> if it contains characters
+// that are not allowed in a bare destination.
+func writeDestination(w *bufio.Writer, dest string) {
+	bare := true
+	for i := 0; i < len(dest) && bare; i++ {
+		switch c := dest[i]; {
+		case c <= ' ', c == '(', c == ')', c == '<', c == '>', c == '\\':
+			bare = false
+		}
+	}
+	if bare {
+		_, _ = w.WriteString(dest)
+		return
+	}
+
+	_ = w.WriteByte('<')
+	for i := 0; i < len(dest); i++ {
+		switch c := dest[i]; c {
+		case '<', '>', '\\':
+			_ = w.WriteByte('\\')
+			_ = w.WriteByte(c)
+		case '\n':
+			_, _ = w.WriteString("%0A")
+		default:
+			_ = w.WriteByte(c)
+		}
+	}
+	_ = w.WriteByte('>')
+}
+
+// escapeMarkdown escapes characters in plain text
+// that would otherwise be interpreted as Markdown inline syntax.
+//
+// If lineStart is true, the text is placed at the start of a block
+// (e.g., right after a list marker),
+// and characters that would start a new block are also escaped.
+func escapeMarkdown(s []byte, lineStart bool) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(s))
+
+	// Position of a character that would start a block
+	// if it's at the start of a line.
+	blockStart := -1
+	if lineStart && len(s) > 0 {
+		switch s[0] {
+		case '#', '>', '-', '+', '=':
+			blockStart = 0
+		default:
+			// Ordered list markers: digits followed by '.' or ')',
+			// and then a space or the end of the text.
+			i := 0
+			for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+				i++
+			}
+			if i > 0 && i < len(s) && (s[i] == '.' || s[i] == ')') &&
+				(i+1 == len(s) || s[i+1] == ' ') {
+				blockStart = i
+			}
+		}
+	}
+
+	for i, c := range s {
+		switch c {
+		case '\\', '`', '*', '_', '[', ']', '<', '>', '&', '~':
+			buf.WriteByte('\\')
+		case '\n':
+			// Titles don't span lines.
+			buf.WriteByte(' ')
+			continue
+		default:
+			if i == blockStart {
+				buf.WriteByte('\\')
+			}
+		}
+		buf.WriteByte(c)
+	}
+	return buf.Bytes()
+}
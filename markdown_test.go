@@ -0,0 +1,390 @@
+package toc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"pgregory.net/rapid"
+)
+
+func TestMarkdownRenderer(t *testing.T) {
+	t.Parallel()
+
+	tree := Items{
+		item("Foo", "foo",
+			item("Bar", "bar",
+				item("Baz", "baz"))),
+		item("Qux", ""),
+	}
+
+	tests := []struct {
+		desc     string
+		renderer MarkdownRenderer
+		give     Items
+		want     string
+	}{
+		{
+			desc: "empty",
+			want: "",
+		},
+		{
+			desc: "default",
+			give: tree,
+			want: joinLines(
+				"- [Foo](#foo)",
+				"  - [Bar](#bar)",
+				"    - [Baz](#baz)",
+				"- Qux",
+			),
+		},
+		{
+			desc:     "marker",
+			renderer: MarkdownRenderer{Marker: '*'},
+			give:     tree,
+			want: joinLines(
+				"* [Foo](#foo)",
+				"  * [Bar](#bar)",
+				"    * [Baz](#baz)",
+				"* Qux",
+			),
+		},
+		{
+			desc:     "ordered",
+			renderer: MarkdownRenderer{Marker: '.'},
+			give:     tree,
+			want: joinLines(
+				"1. [Foo](#foo)",
+				"   1. [Bar](#bar)",
+				"      1. [Baz](#baz)",
+				"2. Qux",
+			),
+		},
+		{
+			desc:     "ordered/wide",
+			renderer: MarkdownRenderer{Marker: ')'},
+			give: Items{
+				item("1", ""), item("2", ""), item("3", ""),
+				item("4", ""), item("5", ""), item("6", ""),
+				item("7", ""), item("8", ""), item("9", ""),
+				item("10", "", item("10.1", "")),
+			},
+			want: joinLines(
+				"1) 1", "2) 2", "3) 3",
+				"4) 4", "5) 5", "6) 6",
+				"7) 7", "8) 8", "9) 9",
+				"10) 10",
+				"    1) 10.1",
+			),
+		},
+		{
+			desc:     "indent",
+			renderer: MarkdownRenderer{Indent: 4},
+			give:     tree,
+			want: joinLines(
+				"- [Foo](#foo)",
+				"    - [Bar](#bar)",
+				"        - [Baz](#baz)",
+				"- Qux",
+			),
+		},
+		{
+			desc:     "indent/too small",
+			renderer: MarkdownRenderer{Marker: '.', Indent: 1},
+			give:     tree,
+			want: joinLines(
+				"1. [Foo](#foo)",
+				"   1. [Bar](#bar)",
+				"      1. [Baz](#baz)",
+				"2. Qux",
+			),
+		},
+		{
+			desc:     "indent/too large",
+			renderer: MarkdownRenderer{Indent: 8},
+			give:     tree,
+			want: joinLines(
+				"- [Foo](#foo)",
+				"     - [Bar](#bar)",
+				"          - [Baz](#baz)",
+				"- Qux",
+			),
+		},
+		{
+			desc:     "reference links",
+			renderer: MarkdownRenderer{LinkStyle: LinkReference},
+			give: Items{
+				item("Foo", "foo",
+					item("Bar", "bar")),
+				item("Foo again", "foo"),
+				item("Different case", "FOO"),
+			},
+			want: joinLines(
+				"- [Foo][foo]",
+				"  - [Bar][bar]",
+				"- [Foo again][foo]",
+				"- [Different case](#FOO)",
+				"",
+				"[foo]: #foo",
+				"[bar]: #bar",
+			),
+		},
+		{
+			desc:     "reference links/urls",
+			renderer: MarkdownRenderer{LinkStyle: LinkReference},
+			give: Items{
+				{Title: []byte("Foo"), ID: []byte("foo"), URL: []byte("a.html#foo")},
+				{Title: []byte("Foo in b"), ID: []byte("foo"), URL: []byte("b.html#foo")},
+				{Title: []byte("B"), URL: []byte("b.html")},
+			},
+			want: joinLines(
+				"- [Foo][foo]",
+				"- [Foo in b](b.html#foo)",
+				"- [B](b.html)",
+				"",
+				"[foo]: a.html#foo",
+			),
+		},
+		{
+			desc:     "base url",
+			renderer: MarkdownRenderer{BaseURL: "guide.html"},
+			give: Items{
+				item("Foo", "foo"),
+				{Title: []byte("Bar"), URL: []byte("bar.html")},
+			},
+			want: joinLines(
+				"- [Foo](guide.html#foo)",
+				"- [Bar](bar.html)",
+			),
+		},
+		{
+			desc: "link func",
+			renderer: MarkdownRenderer{
+				LinkFunc: func(it *Item) []byte {
+					if len(it.ID) == 0 {
+						return nil
+					}
+					return append([]byte("/docs/"), it.ID...)
+				},
+			},
+			give: Items{
+				item("Foo", "foo"),
+				item("Bar", ""),
+			},
+			want: joinLines(
+				"- [Foo](/docs/foo)",
+				"- Bar",
+			),
+		},
+		{
+			desc: "empty items",
+			give: Items{
+				item("Foo", "foo",
+					item("", "",
+						item("Bar", "bar"))),
+				item("", "",
+					item("", "",
+						item("Baz", "baz"),
+						item("Qux", "qux"))),
+				item("", ""),
+			},
+			want: joinLines(
+				"- [Foo](#foo)",
+				"  - - [Bar](#bar)",
+				"- - - [Baz](#baz)",
+				"    - [Qux](#qux)",
+				"-",
+			),
+		},
+		{
+			desc: "numbers",
+			give: Items{
+				numberedItem(item("Foo", "foo",
+					numberedItem(item("Bar", ""), 1, 1)), 1),
+			},
+			want: joinLines(
+				"- [1 Foo](#foo)",
+				"  - 1.1 Bar",
+			),
+		},
+		{
+			desc:     "numbers/format",
+			renderer: MarkdownRenderer{NumberFormat: FormatNumber(UpperRoman)},
+			give: Items{
+				numberedItem(item("Foo", "foo"), 4),
+			},
+			want: joinLines(
+				"- [IV Foo](#foo)",
+			),
+		},
+		{
+			desc: "escaping",
+			give: Items{
+				item("The `Render` *method*", "render"),
+				item("[Links] & <tags>", "links"),
+				item(`back\slash ~strike~ _under_`, ""),
+				item("# Not a heading", ""),
+				item("1. Not a list", ""),
+				item("# In a link", "in-a-link"),
+			},
+			want: joinLines(
+				"- [The \\`Render\\` \\*method\\*](#render)",
+				"- [\\[Links\\] \\& \\<tags\\>](#links)",
+				`- back\\slash \~strike\~ \_under\_`,
+				`- \# Not a heading`,
+				`- 1\. Not a list`,
+				"- [# In a link](#in-a-link)",
+			),
+		},
+		{
+			desc: "destination",
+			give: Items{
+				item("Foo", "foo bar"),
+				item("Bar", "a(b)"),
+				item("Baz", `a<b>\c`),
+			},
+			want: joinLines(
+				"- [Foo](<#foo bar>)",
+				"- [Bar](<#a(b)>)",
+				`- [Baz](<#a\<b\>\\c>)`,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, tt.renderer.Render(&buf, &TOC{Items: tt.give}))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_nil(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, new(MarkdownRenderer).Render(&buf, nil))
+	assert.Empty(t, buf.String())
+}
+
+func TestMarkdownRendererRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	rapid.Check(t, testMarkdownRendererRoundTrip)
+}
+
+func FuzzMarkdownRendererRoundTrip(f *testing.F) {
+	f.Fuzz(rapid.MakeFuzz(testMarkdownRendererRoundTrip))
+}
+
+func testMarkdownRendererRoundTrip(t *rapid.T) {
+	titleChars := []rune("abcXYZ019 .-+=#>*_`[]()<>&;!~\\:")
+	idChars := []rune("abcXYZ019-_ ()")
+
+	// Titles are trimmed by Markdown parsers,
+	// and must not be blank.
+	genTitle := rapid.Custom(func(t *rapid.T) []byte {
+		s := string(rapid.SliceOfN(rapid.SampledFrom(titleChars), 1, 20).Draw(t, "title"))
+		s = strings.Join(strings.Fields(s), " ")
+		if len(s) == 0 {
+			s = "x"
+		}
+		return []byte(s)
+	})
+	genID := rapid.Custom(func(t *rapid.T) []byte {
+		return []byte(string(rapid.SliceOfN(rapid.SampledFrom(idChars), 0, 10).Draw(t, "id")))
+	})
+
+	var genItems func(depth int) *rapid.Generator[Items]
+	genItems = func(depth int) *rapid.Generator[Items] {
+		return rapid.Custom(func(t *rapid.T) Items {
+			maxLen := 3
+			if depth > 3 {
+				maxLen = 0
+			}
+			var items Items
+			for range rapid.IntRange(0, maxLen).Draw(t, "count") {
+				item := new(Item)
+				children := genItems(depth+1).Draw(t, "children")
+				// Items without titles must have children.
+				if len(children) == 0 || rapid.Bool().Draw(t, "titled") {
+					item.Title = genTitle.Draw(t, "title")
+					if id := genID.Draw(t, "id"); len(id) > 0 {
+						item.ID = id
+					}
+				}
+				item.Items = children
+				items = append(items, item)
+			}
+			return items
+		})
+	}
+
+	want := &TOC{Items: genItems(0).Draw(t, "items")}
+	renderer := MarkdownRenderer{
+		Marker:    rapid.SampledFrom([]byte("-*+.)")).Draw(t, "marker"),
+		Indent:    rapid.IntRange(0, 8).Draw(t, "indent"),
+		LinkStyle: rapid.SampledFrom([]LinkStyle{LinkInline, LinkReference}).Draw(t, "linkStyle"),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, renderer.Render(&buf, want))
+
+	src := buf.Bytes()
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	).Parse(text.NewReader(src))
+
+	got := &TOC{}
+	if list := doc.FirstChild(); list != nil {
+		require.IsType(t, (*ast.List)(nil), list, "source:\n%s", src)
+		got.Items = parseMarkdownList(t, src, list)
+	}
+	assert.Equal(t, want, got, "source:\n%s", src)
+}
+
+// parseMarkdownList rebuilds a list of TOC items
+// from a list generated by MarkdownRenderer.
+func parseMarkdownList(t require.TestingT, src []byte, list ast.Node) Items {
+	var items Items
+	for li := list.FirstChild(); li != nil; li = li.NextSibling() {
+		item := new(Item)
+		for c := li.FirstChild(); c != nil; c = c.NextSibling() {
+			switch c := c.(type) {
+			case *ast.List:
+				item.Items = parseMarkdownList(t, src, c)
+			case *ast.TextBlock, *ast.Paragraph:
+				content := c.FirstChild()
+				if link, ok := content.(*ast.Link); ok && content.NextSibling() == nil {
+					item.ID = bytes.TrimPrefix(link.Destination, []byte("#"))
+					if len(item.ID) == 0 {
+						item.ID = nil
+					}
+					content = link
+				}
+				item.Title = util.UnescapePunctuations(nodeText(src, content.Parent()))
+			default:
+				require.Failf(t, "unexpected node", "%T in:\n%s", c, src)
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func numberedItem(it *Item, number ...int) *Item {
+	it.Number = number
+	return it
+}
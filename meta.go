@@ -0,0 +1,125 @@
+package toc
+
+import (
+	"math"
+
+	"github.com/yuin/goldmark/parser"
+)
+
+// _metaKey is the key in document metadata
+// that holds per-document configuration.
+const _metaKey = "toc"
+
+// ConfigureFromMeta builds a function for Transformer.Configure
+// that reads per-document configuration from the document's metadata.
+//
+// get retrieves the metadata for a document from the parser.Context.
+// This is compatible with the Get function of goldmark-meta.
+//
+//	&toc.Transformer{
+//	  Configure: toc.ConfigureFromMeta(meta.Get),
+//	}
+//
+// Configuration is read from the "toc" key of the metadata.
+// Setting it to false disables the table of contents for the document.
+//
+//	---
+//	toc: false
+//	---
+//
+// Setting it to a map changes the Transformer for the document.
+// The following keys are supported:
+//
+//	---
+//	toc:
+//	  title: Contents       # Title
+//	  titleDepth: 2         # TitleDepth
+//	  titleID: contents     # TitleID
+//	  listID: toc-list      # ListID
+//	  minDepth: 2           # MinDepth
+//	  maxDepth: 3           # MaxDepth
+//	  compact: true         # Compact
+//	  numbered: true        # Numbered
+//	  marker: "[TOC]"       # Marker
+//	  position: bottom      # Position
+//	  leadingTitle: omit    # LeadingTitle
+//	---
+//
+// Keys that are missing or have values of the wrong type
+// are ignored.
+func ConfigureFromMeta(get func(parser.Context) map[string]any) func(parser.Context, *Transformer) bool {
+	return func(ctx parser.Context, t *Transformer) bool {
+		meta := get(ctx)
+		if meta == nil {
+			return true
+		}
+
+		switch v := meta[_metaKey].(type) {
+		case bool:
+			return v
+		case map[string]any:
+			configureFromMap(t, func(key string) any { return v[key] })
+		case map[any]any:
+			configureFromMap(t, func(key string) any { return v[key] })
+		}
+		return true
+	}
+}
+
+func configureFromMap(t *Transformer, get func(string) any) {
+	metaString(get("title"), &t.Title)
+	metaInt(get("titleDepth"), &t.TitleDepth)
+	metaString(get("titleID"), &t.TitleID)
+	metaString(get("listID"), &t.ListID)
+	metaInt(get("minDepth"), &t.MinDepth)
+	metaInt(get("maxDepth"), &t.MaxDepth)
+	metaBool(get("compact"), &t.Compact)
+	metaBool(get("numbered"), &t.Numbered)
+	metaString(get("marker"), &t.Marker)
+
+	if s, ok := get("position").(string); ok {
+		var pos Position
+		if err := pos.UnmarshalText([]byte(s)); err == nil {
+			t.Position = pos
+		}
+	}
+
+	if s, ok := get("leadingTitle").(string); ok {
+		var lt LeadingTitle
+		if err := lt.UnmarshalText([]byte(s)); err == nil {
+			t.LeadingTitle = lt
+		}
+	}
+}
+
+func metaString(v any, dst *string) {
+	if s, ok := v.(string); ok {
+		*dst = s
+	}
+}
+
+func metaBool(v any, dst *bool) {
+	if b, ok := v.(bool); ok {
+		*dst = b
+	}
+}
+
+// metaInt reads an integer from a metadata value.
+// YAML and JSON decoders produce different numeric types,
+// so this accepts all of them.
+func metaInt(v any, dst *int) {
+	switch v := v.(type) {
+	case int:
+		*dst = v
+	case int64:
+		*dst = int(v)
+	case uint64:
+		if v <= math.MaxInt {
+			*dst = int(v)
+		}
+	case float64:
+		if v == math.Trunc(v) {
+			*dst = int(v)
+		}
+	}
+}
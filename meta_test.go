@@ -0,0 +1,139 @@
+package toc_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"go.abhg.dev/goldmark/toc"
+)
+
+var _metaContextKey = parser.NewContextKey()
+
+// getMeta mimics goldmark-meta's Get function.
+func getMeta(ctx parser.Context) map[string]any {
+	meta, _ := ctx.Get(_metaContextKey).(map[string]any)
+	return meta
+}
+
+func TestConfigureFromMeta(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("# Foo\n\n## Bar\n\n[TOC]\n\n### Baz\n")
+
+	tests := []struct {
+		desc string
+		meta map[string]any
+		want string
+	}{
+		{
+			desc: "no metadata",
+			want: "<h1 id=\"table-of-contents\">Table of Contents</h1>\n" +
+				"<ul>\n<li>\n<a href=\"#foo\">Foo</a><ul>\n<li>\n" +
+				"<a href=\"#bar\">Bar</a><ul>\n<li>\n<a href=\"#baz\">Baz</a></li>\n</ul>\n</li>\n</ul>\n</li>\n</ul>\n" +
+				"<h1 id=\"foo\">Foo</h1>\n<h2 id=\"bar\">Bar</h2>\n<p>[TOC]</p>\n<h3 id=\"baz\">Baz</h3>\n",
+		},
+		{
+			desc: "disabled",
+			meta: map[string]any{"toc": false},
+			want: "<h1 id=\"foo\">Foo</h1>\n<h2 id=\"bar\">Bar</h2>\n<p>[TOC]</p>\n<h3 id=\"baz\">Baz</h3>\n",
+		},
+		{
+			desc: "enabled",
+			meta: map[string]any{"toc": true, "title": "ignored"},
+			want: "<h1 id=\"table-of-contents\">Table of Contents</h1>\n" +
+				"<ul>\n<li>\n<a href=\"#foo\">Foo</a><ul>\n<li>\n" +
+				"<a href=\"#bar\">Bar</a><ul>\n<li>\n<a href=\"#baz\">Baz</a></li>\n</ul>\n</li>\n</ul>\n</li>\n</ul>\n" +
+				"<h1 id=\"foo\">Foo</h1>\n<h2 id=\"bar\">Bar</h2>\n<p>[TOC]</p>\n<h3 id=\"baz\">Baz</h3>\n",
+		},
+		{
+			desc: "string keys",
+			meta: map[string]any{
+				"toc": map[string]any{
+					"title":      "Contents",
+					"titleDepth": 2,
+					"titleID":    "contents",
+					"listID":     "toc",
+					"minDepth":   2,
+					"maxDepth":   float64(2),
+					"numbered":   true,
+					"marker":     "[TOC]",
+				},
+			},
+			want: "<h1 id=\"foo\">Foo</h1>\n<h2 id=\"bar\">Bar</h2>\n" +
+				"<h2 id=\"contents\">Contents</h2>\n" +
+				"<ul id=\"toc\">\n<li>\n<ul>\n<li>\n<a href=\"#bar\">1 Bar</a></li>\n</ul>\n</li>\n</ul>\n" +
+				"<h3 id=\"baz\">Baz</h3>\n",
+		},
+		{
+			// goldmark-meta uses yaml.v2,
+			// which decodes nested maps as map[any]any.
+			desc: "any keys",
+			meta: map[string]any{
+				"toc": map[any]any{
+					"minDepth": int64(2),
+					"compact":  true,
+					"position": "bottom",
+				},
+			},
+			want: "<h1 id=\"foo\">Foo</h1>\n<h2 id=\"bar\">Bar</h2>\n<p>[TOC]</p>\n<h3 id=\"baz\">Baz</h3>\n" +
+				"<h1 id=\"table-of-contents\">Table of Contents</h1>\n" +
+				"<ul>\n<li>\n<a href=\"#bar\">Bar</a><ul>\n<li>\n<a href=\"#baz\">Baz</a></li>\n</ul>\n</li>\n</ul>\n",
+		},
+		{
+			desc: "leading title",
+			meta: map[string]any{
+				"toc": map[string]any{
+					"leadingTitle": "omit",
+					"position":     "after-title",
+				},
+			},
+			want: "<h1 id=\"foo\">Foo</h1>\n" +
+				"<h1 id=\"table-of-contents\">Table of Contents</h1>\n" +
+				"<ul>\n<li>\n<a href=\"#bar\">Bar</a><ul>\n<li>\n<a href=\"#baz\">Baz</a></li>\n</ul>\n</li>\n</ul>\n" +
+				"<h2 id=\"bar\">Bar</h2>\n<p>[TOC]</p>\n<h3 id=\"baz\">Baz</h3>\n",
+		},
+		{
+			desc: "invalid values",
+			meta: map[string]any{
+				"toc": map[string]any{
+					"title":        42,
+					"maxDepth":     "two",
+					"compact":      "yes",
+					"position":     "sideways",
+					"leadingTitle": "drop",
+				},
+			},
+			want: "<h1 id=\"table-of-contents\">Table of Contents</h1>\n" +
+				"<ul>\n<li>\n<a href=\"#foo\">Foo</a><ul>\n<li>\n" +
+				"<a href=\"#bar\">Bar</a><ul>\n<li>\n<a href=\"#baz\">Baz</a></li>\n</ul>\n</li>\n</ul>\n</li>\n</ul>\n" +
+				"<h1 id=\"foo\">Foo</h1>\n<h2 id=\"bar\">Bar</h2>\n<p>[TOC]</p>\n<h3 id=\"baz\">Baz</h3>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(
+				goldmark.WithExtensions(&toc.Extender{
+					Configure: toc.ConfigureFromMeta(getMeta),
+				}),
+				goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			)
+
+			ctx := parser.NewContext()
+			if tt.meta != nil {
+				ctx.Set(_metaContextKey, tt.meta)
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert(src, &buf, parser.WithContext(ctx)))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
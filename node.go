@@ -0,0 +1,120 @@
+package toc
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindNode is the ast.NodeKind of Node.
+var KindNode = ast.NewNodeKind("TOC")
+
+// Node is a block node that holds a rendered table of contents.
+// Its children are the title heading (if any)
+// and the list built from the table of contents.
+//
+// The Transformer wraps the table of contents in a Node
+// if Transformer.Wrap is set.
+// This allows renderers and other AST transformers
+// to tell the table of contents apart from the rest of the document.
+//
+// Goldmark's renderer cannot render nodes of unknown kinds.
+// If a document contains a Node,
+// register a renderer for it (e.g. HTMLRenderer) with the goldmark renderer.
+// The Extender does this automatically if Extender.Nav is set.
+type Node struct {
+	ast.BaseBlock
+
+	// TOC is the table of contents rendered inside this node.
+	TOC *TOC
+}
+
+var _ ast.Node = (*Node)(nil)
+
+// NewNode builds a new Node for the given table of contents.
+// The title and list for the table of contents
+// must be added to it as children.
+func NewNode(toc *TOC) *Node {
+	return &Node{TOC: toc}
+}
+
+// Kind reports the kind of this node.
+func (n *Node) Kind() ast.NodeKind {
+	return KindNode
+}
+
+// Dump dumps the Node to stdout.
+func (n *Node) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, nil, nil)
+}
+
+// HTMLRenderer renders Node into HTML as a <nav> element.
+//
+// For example, with ID "toc" and Label "Table of Contents",
+// a table of contents will be rendered as:
+//
+//	<nav id="toc" aria-label="Table of Contents">
+//	<h1 id="table-of-contents">Table of Contents</h1>
+//	<ul>
+//	  ...
+//	</ul>
+//	</nav>
+//
+// Install it on the goldmark renderer like so:
+//
+//	markdown := goldmark.New(...)
+//	markdown.Renderer().AddOptions(
+//	  renderer.WithNodeRenderers(
+//	    util.Prioritized(&toc.HTMLRenderer{}, 100),
+//	  ),
+//	)
+type HTMLRenderer struct {
+	// ID is the id attribute of the <nav> element.
+	//
+	// The element does not have an id if ID is empty.
+	ID string
+
+	// Class is the class attribute of the <nav> element.
+	//
+	// The element does not have a class if Class is empty.
+	Class string
+
+	// Label is the aria-label attribute of the <nav> element.
+	//
+	// The element does not have an aria-label if Label is empty.
+	Label string
+}
+
+var _ renderer.NodeRenderer = (*HTMLRenderer)(nil)
+
+// RegisterFuncs registers the rendering function for Node
+// with the goldmark renderer.
+func (r *HTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindNode, r.renderNode)
+}
+
+func (r *HTMLRenderer) renderNode(w util.BufWriter, _ []byte, _ ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</nav>\n")
+		return ast.WalkContinue, nil
+	}
+
+	_, _ = w.WriteString("<nav")
+	writeAttr(w, "id", r.ID)
+	writeAttr(w, "class", r.Class)
+	writeAttr(w, "aria-label", r.Label)
+	_, _ = w.WriteString(">\n")
+	return ast.WalkContinue, nil
+}
+
+func writeAttr(w util.BufWriter, name, value string) {
+	if len(value) == 0 {
+		return
+	}
+
+	_ = w.WriteByte(' ')
+	_, _ = w.WriteString(name)
+	_, _ = w.WriteString(`="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(value)))
+	_ = w.WriteByte('"')
+}
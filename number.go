@@ -0,0 +1,145 @@
+package toc
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumberFormat formats the section number of an item for display.
+//
+// For example, a NumberFormat could format []int{3, 2, 1} as "3.2.1".
+type NumberFormat func(number []int) string
+
+// Numeral formats a single component of a section number.
+// The component is always 1 or greater.
+type Numeral func(n int) string
+
+var _ Numeral = Decimal
+
+// FormatNumber builds a NumberFormat that formats each component
+// of a section number with the corresponding Numeral,
+// and joins them with ".".
+// The last Numeral is used for all remaining components.
+//
+// For example,
+//
+//	FormatNumber(Decimal)               // 3.2.1
+//	FormatNumber(UpperLetter, Decimal)  // C.2.1
+//	FormatNumber(UpperRoman, Decimal)   // III.2.1
+//
+// FormatNumber uses Decimal if no Numerals are provided.
+func FormatNumber(numerals ...Numeral) NumberFormat {
+	if len(numerals) == 0 {
+		numerals = []Numeral{Decimal}
+	}
+
+	return func(number []int) string {
+		var sb strings.Builder
+		for i, n := range number {
+			if i > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(numerals[min(i, len(numerals)-1)](n))
+		}
+		return sb.String()
+	}
+}
+
+// Decimal formats a number as a decimal: 1, 2, 3, ...
+func Decimal(n int) string {
+	return strconv.Itoa(n)
+}
+
+// UpperLetter formats a number as an uppercase letter:
+// A, B, C, ..., Z, AA, AB, ...
+func UpperLetter(n int) string {
+	return letter(n, 'A')
+}
+
+// LowerLetter formats a number as a lowercase letter:
+// a, b, c, ..., z, aa, ab, ...
+func LowerLetter(n int) string {
+	return letter(n, 'a')
+}
+
+func letter(n int, base byte) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+
+	var buf []byte
+	for n > 0 {
+		n--
+		buf = append(buf, base+byte(n%26))
+		n /= 26
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
+
+// UpperRoman formats a number as an uppercase Roman numeral:
+// I, II, III, IV, ...
+//
+// Numbers that cannot be represented as Roman numerals
+// are formatted as decimals.
+func UpperRoman(n int) string {
+	return roman(n)
+}
+
+// LowerRoman formats a number as a lowercase Roman numeral:
+// i, ii, iii, iv, ...
+//
+// Numbers that cannot be represented as Roman numerals
+// are formatted as decimals.
+func LowerRoman(n int) string {
+	return strings.ToLower(roman(n))
+}
+
+var _romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"},
+	{1, "I"},
+}
+
+func roman(n int) string {
+	if n < 1 || n > 3999 {
+		return strconv.Itoa(n)
+	}
+
+	var sb strings.Builder
+	for _, r := range _romanNumerals {
+		for n >= r.value {
+			sb.WriteString(r.symbol)
+			n -= r.value
+		}
+	}
+	return sb.String()
+}
+
+// numberItems assigns section numbers to the given items.
+//
+// Items without titles don't consume numbers.
+// Their children are numbered as if they were siblings of the item.
+func numberItems(items Items, parent []int) {
+	var counter int
+	numberItemsWith(items, parent, &counter)
+}
+
+func numberItemsWith(items Items, parent []int, counter *int) {
+	for _, item := range items {
+		if len(item.Title) == 0 {
+			numberItemsWith(item.Items, parent, counter)
+			continue
+		}
+
+		*counter++
+		item.Number = append(parent[:len(parent):len(parent)], *counter)
+		numberItems(item.Items, item.Number)
+	}
+}
@@ -0,0 +1,82 @@
+package toc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumerals(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give int
+
+		decimal     string
+		upperLetter string
+		lowerLetter string
+		upperRoman  string
+		lowerRoman  string
+	}{
+		{give: 1, decimal: "1", upperLetter: "A", lowerLetter: "a", upperRoman: "I", lowerRoman: "i"},
+		{give: 4, decimal: "4", upperLetter: "D", lowerLetter: "d", upperRoman: "IV", lowerRoman: "iv"},
+		{give: 9, decimal: "9", upperLetter: "I", lowerLetter: "i", upperRoman: "IX", lowerRoman: "ix"},
+		{give: 26, decimal: "26", upperLetter: "Z", lowerLetter: "z", upperRoman: "XXVI", lowerRoman: "xxvi"},
+		{give: 27, decimal: "27", upperLetter: "AA", lowerLetter: "aa", upperRoman: "XXVII", lowerRoman: "xxvii"},
+		{give: 52, decimal: "52", upperLetter: "AZ", lowerLetter: "az", upperRoman: "LII", lowerRoman: "lii"},
+		{give: 703, decimal: "703", upperLetter: "AAA", lowerLetter: "aaa", upperRoman: "DCCIII", lowerRoman: "dcciii"},
+		{give: 1994, decimal: "1994", upperLetter: "BXR", lowerLetter: "bxr", upperRoman: "MCMXCIV", lowerRoman: "mcmxciv"},
+		{give: 4000, decimal: "4000", upperLetter: "EWV", lowerLetter: "ewv", upperRoman: "4000", lowerRoman: "4000"},
+		{give: 0, decimal: "0", upperLetter: "0", lowerLetter: "0", upperRoman: "0", lowerRoman: "0"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprint(tt.give), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.decimal, Decimal(tt.give), "Decimal")
+			assert.Equal(t, tt.upperLetter, UpperLetter(tt.give), "UpperLetter")
+			assert.Equal(t, tt.lowerLetter, LowerLetter(tt.give), "LowerLetter")
+			assert.Equal(t, tt.upperRoman, UpperRoman(tt.give), "UpperRoman")
+			assert.Equal(t, tt.lowerRoman, LowerRoman(tt.give), "LowerRoman")
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		numerals []Numeral
+		give     []int
+		want     string
+	}{
+		{desc: "default", give: []int{3, 2, 1}, want: "3.2.1"},
+		{desc: "single", numerals: []Numeral{Decimal}, give: []int{3}, want: "3"},
+		{desc: "empty", numerals: []Numeral{Decimal}, give: nil, want: ""},
+		{
+			desc:     "appendix",
+			numerals: []Numeral{UpperLetter, Decimal},
+			give:     []int{3, 2, 1},
+			want:     "C.2.1",
+		},
+		{
+			desc:     "roman",
+			numerals: []Numeral{UpperRoman, LowerLetter, LowerRoman},
+			give:     []int{3, 2, 4, 5},
+			want:     "III.b.iv.v",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, FormatNumber(tt.numerals...)(tt.give))
+		})
+	}
+}
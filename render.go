@@ -4,6 +4,8 @@ import "github.com/yuin/goldmark/ast"
 
 const _defaultMarker = '*'
 
+var _defaultNumberFormat = FormatNumber(Decimal)
+
 // RenderList renders a table of contents as a nested list with a sane,
 // default configuration for the ListRenderer.
 //
@@ -43,6 +45,28 @@ type ListRenderer struct {
 	//
 	// Defaults to '*'.
 	Marker byte
+
+	// NumberFormat formats section numbers of items
+	// that have them (see Numbered).
+	// The formatted number is placed before the title,
+	// separated by a space.
+	//
+	// Defaults to FormatNumber(Decimal), which renders "3.2.1".
+	NumberFormat NumberFormat
+
+	// BaseURL is placed before links to headings.
+	// Use this if the table of contents is rendered
+	// on a different page than the document.
+	// For example, with BaseURL "/guide/",
+	// items link to "/guide/#foo" instead of "#foo".
+	//
+	// Items with a URL link to it unchanged.
+	BaseURL string
+
+	// LinkFunc, if set, decides the link destination for each item,
+	// overriding BaseURL and the URLs of items.
+	// Items for which it returns nil are not links.
+	LinkFunc LinkFunc
 }
 
 // Render renders the table of contents into Markdown.
@@ -76,19 +100,43 @@ func (r *ListRenderer) renderItems(items Items) ast.Node {
 	return list
 }
 
+func (r *ListRenderer) formatNumber(number []int) string {
+	format := r.NumberFormat
+	if format == nil {
+		format = _defaultNumberFormat
+	}
+	return format(number)
+}
+
 func (r *ListRenderer) renderItem(n *Item) ast.Node {
 	item := ast.NewListItem(0)
 
-	if t := n.Title; len(t) > 0 {
-		title := ast.NewString(t)
-		title.SetRaw(true)
-		if len(n.ID) > 0 {
+	if t := n.Title; len(t) > 0 || n.RichTitle != nil {
+		// Inline content is placed directly inside the list item,
+		// or inside a link if the item has an ID or URL.
+		var parent ast.Node = item
+		if dest := itemLink(n, r.BaseURL, r.LinkFunc); len(dest) > 0 {
 			link := ast.NewLink()
-			link.Destination = append([]byte("#"), n.ID...)
-			link.AppendChild(link, title)
+			link.Destination = dest
 			item.AppendChild(item, link)
+			parent = link
+		}
+
+		if rich := n.RichTitle; rich != nil {
+			if len(n.Number) > 0 {
+				number := ast.NewString([]byte(r.formatNumber(n.Number) + " "))
+				number.SetRaw(true)
+				parent.AppendChild(parent, number)
+			}
+			appendInlines(nil, parent, rich, nil)
 		} else {
-			item.AppendChild(item, title)
+			if len(n.Number) > 0 {
+				t = append([]byte(r.formatNumber(n.Number)+" "), t...)
+			}
+
+			title := ast.NewString(t)
+			title.SetRaw(true)
+			parent.AppendChild(parent, title)
 		}
 	}
 
@@ -261,6 +261,87 @@ func TestRenderList_id(t *testing.T) {
 	assert.Contains(t, buf.String(), `<ul id="toc">`)
 }
 
+func TestRenderList_links(t *testing.T) {
+	t.Parallel()
+
+	withURL := func(it *Item, url string) *Item {
+		it.URL = []byte(url)
+		return it
+	}
+
+	toc := &TOC{
+		Items: Items{
+			item("Foo", "foo",
+				withURL(item("Bar", "bar"), "https://example.com/bar")),
+			item("Baz", ""),
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		renderer ListRenderer
+		want     list
+	}{
+		{
+			desc: "default",
+			want: list{
+				{
+					Text: "Foo",
+					Href: "#foo",
+					List: list{
+						{Text: "Bar", Href: "https://example.com/bar"},
+					},
+				},
+				{Text: "Baz"},
+			},
+		},
+		{
+			desc:     "base url",
+			renderer: ListRenderer{BaseURL: "/guide/"},
+			want: list{
+				{
+					Text: "Foo",
+					Href: "/guide/#foo",
+					List: list{
+						{Text: "Bar", Href: "https://example.com/bar"},
+					},
+				},
+				{Text: "Baz"},
+			},
+		},
+		{
+			desc: "link func",
+			renderer: ListRenderer{
+				BaseURL: "/guide/",
+				LinkFunc: func(it *Item) []byte {
+					if string(it.Title) == "Foo" {
+						return nil
+					}
+					return append([]byte("page:"), it.Title...)
+				},
+			},
+			want: list{
+				{
+					Text: "Foo",
+					List: list{
+						{Text: "Bar", Href: "page:Bar"},
+					},
+				},
+				{Text: "Baz", Href: "page:Baz"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tt.want.Match(t, tt.renderer.Render(toc))
+		})
+	}
+}
+
 func TestRenderList_nil(t *testing.T) {
 	t.Parallel()
 
@@ -286,3 +367,52 @@ func TestOrderedList(t *testing.T) {
 	assert.Contains(t, buf.String(), `<ol>`)
 	assert.NotContains(t, buf.String(), "start=")
 }
+
+func TestRenderList_numbers(t *testing.T) {
+	t.Parallel()
+
+	numbered := func(it *Item, number ...int) *Item {
+		it.Number = number
+		return it
+	}
+
+	toc := &TOC{
+		Items: Items{
+			numbered(item("Foo", "foo",
+				numbered(item("Bar", "bar"), 1, 1),
+			), 1),
+			numbered(item("Baz", ""), 2),
+		},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		list{
+			{
+				Text: "1 Foo",
+				Href: "#foo",
+				List: list{
+					{Text: "1.1 Bar", Href: "#bar"},
+				},
+			},
+			{Text: "2 Baz"},
+		}.Match(t, RenderList(toc))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Parallel()
+
+		renderer := ListRenderer{NumberFormat: FormatNumber(UpperLetter, Decimal)}
+		list{
+			{
+				Text: "A Foo",
+				Href: "#foo",
+				List: list{
+					{Text: "A.1 Bar", Href: "#bar"},
+				},
+			},
+			{Text: "B Baz"},
+		}.Match(t, renderer.Render(toc))
+	})
+}
@@ -0,0 +1,356 @@
+package toc
+
+import (
+	"io/fs"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// PageTitle specifies how Site titles the items for pages.
+type PageTitle int
+
+const (
+	// PageTitleHeading titles each page with its first heading.
+	// If the first heading is a top-level item,
+	// it's replaced by the page's item,
+	// and the headings under it are placed directly under the page.
+	//
+	// Pages without headings are titled with their file names.
+	//
+	// This is the default.
+	PageTitleHeading PageTitle = iota
+
+	// PageTitleFileName titles each page with its file name
+	// without the extension, e.g. "install" for "guide/install.md".
+	PageTitleFileName
+)
+
+// SiteOrder specifies the order of pages in a Site,
+// and which pages are included.
+//
+// Use OrderByName, OrderList, or OrderSummary to build one.
+type SiteOrder interface {
+	// arrange returns the entries of the site's table of contents
+	// given the paths of its pages in file name order.
+	arrange(paths []string) []siteEntry
+}
+
+// siteEntry is an entry in a site's table of contents.
+type siteEntry struct {
+	// Path of the page, or empty for entries
+	// that are only titles.
+	path string
+
+	// Title of entries without pages.
+	title []byte
+
+	children []siteEntry
+}
+
+// OrderByName orders all pages of a site by their paths.
+//
+// This is the default.
+func OrderByName() SiteOrder {
+	return orderByName{}
+}
+
+type orderByName struct{}
+
+func (orderByName) arrange(paths []string) []siteEntry {
+	entries := make([]siteEntry, len(paths))
+	for i, p := range paths {
+		entries[i] = siteEntry{path: p}
+	}
+	return entries
+}
+
+// OrderList orders the pages of a site in the given order.
+// Paths are relative to the root of the site.
+//
+// Pages that are not listed are left out.
+func OrderList(paths ...string) SiteOrder {
+	entries := make([]siteEntry, len(paths))
+	for i, p := range paths {
+		entries[i] = siteEntry{path: cleanPagePath(p)}
+	}
+	return orderEntries(entries)
+}
+
+// OrderSummary orders the pages of a site as listed in
+// the given SUMMARY.md-style index file.
+//
+// The index lists links to pages in the order they should appear.
+// Nested lists place pages under other pages,
+// and list items without links become items with only a title.
+//
+//	# Summary
+//
+//	[Introduction](README.md)
+//
+//	- [Installation](install/README.md)
+//	  - [Linux](install/linux.md)
+//	  - [macOS](install/macos.md)
+//	- Reference
+//	  - [Configuration](reference/config.md)
+//
+// Links are relative to the root of the site.
+// Pages that are not listed are left out.
+// Headings and other content in the index are ignored.
+func OrderSummary(src []byte) SiteOrder {
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+	var entries []siteEntry
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		switch n := n.(type) {
+		case *ast.List:
+			entries = append(entries, summaryList(src, n)...)
+
+		case *ast.Paragraph:
+			// Links outside lists, e.g. for an introduction.
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				if link, ok := c.(*ast.Link); ok {
+					entries = append(entries, siteEntry{path: summaryPath(link)})
+				}
+			}
+		}
+	}
+	return orderEntries(entries)
+}
+
+// summaryList returns entries for the items of a list
+// in a SUMMARY.md-style index.
+func summaryList(src []byte, list *ast.List) []siteEntry {
+	var entries []siteEntry
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		var entry siteEntry
+		for c := item.FirstChild(); c != nil; c = c.NextSibling() {
+			switch c := c.(type) {
+			case *ast.List:
+				entry.children = append(entry.children, summaryList(src, c)...)
+
+			case *ast.TextBlock, *ast.Paragraph:
+				if len(entry.path) > 0 || len(entry.title) > 0 {
+					continue
+				}
+				if link, ok := c.FirstChild().(*ast.Link); ok {
+					entry.path = summaryPath(link)
+				}
+				if len(entry.path) == 0 {
+					entry.title = nodeText(src, c)
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// summaryPath returns the path of the page
+// that a link in a SUMMARY.md-style index refers to.
+func summaryPath(link *ast.Link) string {
+	dest := string(link.Destination)
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		dest = dest[:i]
+	}
+	if p, err := url.PathUnescape(dest); err == nil {
+		dest = p
+	}
+	if len(dest) == 0 {
+		return ""
+	}
+	return cleanPagePath(dest)
+}
+
+// orderEntries is a SiteOrder with fixed entries.
+type orderEntries []siteEntry
+
+func (o orderEntries) arrange([]string) []siteEntry {
+	return o
+}
+
+// Site builds a table of contents for a collection of Markdown documents,
+// such as a book or a documentation website.
+//
+// Each document becomes an item in the table of contents,
+// with the headings of the document nested under it.
+// Items link to the documents' pages,
+// e.g. "install.html" and "install.html#linux".
+//
+//	var site toc.Site
+//	if err := site.ParseFS(markdown, os.DirFS("docs")); err != nil {
+//		// ...
+//	}
+//	nav := site.TOC()
+type Site struct {
+	// Order specifies the order of pages,
+	// and which pages are included.
+	//
+	// Defaults to OrderByName().
+	Order SiteOrder
+
+	// PageTitle specifies how pages are titled.
+	//
+	// Defaults to PageTitleHeading.
+	PageTitle PageTitle
+
+	// PageURL returns the URL of a page given its path.
+	//
+	// Defaults to replacing the extension of the path with ".html",
+	// e.g. "guide/install.html" for "guide/install.md".
+	PageURL func(path string) string
+
+	pages map[string]*TOC // path => TOC
+}
+
+// Add adds a page to the site with the given path
+// and the table of contents of its document.
+// The path is relative to the root of the site,
+// and uses forward slashes, e.g. "guide/install.md".
+//
+// A page added with a path that was already added replaces it.
+func (s *Site) Add(path string, toc *TOC) {
+	if s.pages == nil {
+		s.pages = make(map[string]*TOC)
+	}
+	s.pages[cleanPagePath(path)] = toc
+}
+
+// Parse parses a Markdown document with the given goldmark.Markdown,
+// inspects it with the given options,
+// and adds it to the site as a page with the given path.
+//
+// Enable AutoHeadingID in the Markdown's parser
+// so that items link to headings.
+// The Markdown should not include the Extender
+// or its table of contents will become part of the page.
+func (s *Site) Parse(md goldmark.Markdown, path string, src []byte, opts ...InspectOption) error {
+	doc := md.Parser().Parse(text.NewReader(src))
+	toc, err := Inspect(doc, src, opts...)
+	if err != nil {
+		return err
+	}
+	s.Add(path, toc)
+	return nil
+}
+
+// ParseFS parses all Markdown documents (files ending with .md or .markdown)
+// in the given file system with Parse,
+// and adds them to the site with their paths in the file system.
+func (s *Site) ParseFS(md goldmark.Markdown, fsys fs.FS, opts ...InspectOption) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		switch path.Ext(p) {
+		case ".md", ".markdown":
+		default:
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return s.Parse(md, p, src, opts...)
+	})
+}
+
+// TOC builds the table of contents for the site.
+//
+// The returned table of contents doesn't share memory
+// with those of the pages.
+func (s *Site) TOC() *TOC {
+	paths := make([]string, 0, len(s.pages))
+	for p := range s.pages {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	order := s.Order
+	if order == nil {
+		order = OrderByName()
+	}
+	return &TOC{Items: s.items(order.arrange(paths))}
+}
+
+func (s *Site) items(entries []siteEntry) Items {
+	var items Items
+	for _, e := range entries {
+		children := s.items(e.children)
+		if len(e.path) == 0 {
+			items = append(items, &Item{Title: e.title, Items: children})
+			continue
+		}
+
+		toc, ok := s.pages[e.path]
+		if !ok {
+			// The page doesn't exist.
+			// Place the pages under it in its place.
+			items = append(items, children...)
+			continue
+		}
+
+		item := s.pageItem(e.path, toc)
+		item.Items = append(item.Items, children...)
+		items = append(items, item)
+	}
+	return items
+}
+
+// pageItem builds the item for a page and its headings.
+func (s *Site) pageItem(p string, toc *TOC) *Item {
+	pageURL := s.pageURL(p)
+	items := toc.items()
+	items.Walk(func(item *Item, _ int) WalkStatus {
+		if len(item.ID) > 0 {
+			item.URL = []byte(pageURL + "#" + string(item.ID))
+		}
+		return WalkContinue
+	})
+
+	page := &Item{URL: []byte(pageURL)}
+	if s.PageTitle == PageTitleHeading {
+		if len(items) > 0 && len(items[0].Title) > 0 {
+			first := items[0]
+			page.Title = first.Title
+			page.ID = first.ID
+			items = slices.Concat(first.Items, items[1:])
+		} else {
+			for item := range items.All() {
+				if len(item.Title) > 0 {
+					page.Title = slices.Clone(item.Title)
+					break
+				}
+			}
+		}
+	}
+	if len(page.Title) == 0 {
+		name := path.Base(p)
+		page.Title = []byte(strings.TrimSuffix(name, path.Ext(name)))
+	}
+	page.Items = items
+	return page
+}
+
+func (s *Site) pageURL(p string) string {
+	if s.PageURL != nil {
+		return s.PageURL(p)
+	}
+	return strings.TrimSuffix(p, path.Ext(p)) + ".html"
+}
+
+// cleanPagePath normalizes the path of a page,
+// e.g. "./guide//install.md" becomes "guide/install.md".
+func cleanPagePath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
@@ -0,0 +1,182 @@
+package toc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestSite(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"README.md": {Data: []byte("# Welcome\n\n## Goals\n")},
+		"install.md": {Data: []byte(strings.Join([]string{
+			"# Installation",
+			"## Linux",
+			"## macOS",
+		}, "\n"))},
+		"guide/usage.md":   {Data: []byte("Some text.\n\n## Basics\n")},
+		"guide/empty.md":   {Data: []byte("No headings.\n")},
+		"notes.txt":        {Data: []byte("# Not Markdown\n")},
+		"guide/SUMMARY.md": {Data: []byte("- [Usage](usage.md)\n")},
+	}
+
+	// link builds an item for a heading in a page.
+	link := func(title, id, url string, items ...*Item) *Item {
+		it := item(title, id, items...)
+		it.URL = []byte(url)
+		return it
+	}
+
+	tests := []struct {
+		desc string
+		site Site
+		want Items
+	}{
+		{
+			desc: "default",
+			want: Items{
+				link("Welcome", "welcome", "README.html",
+					link("Goals", "goals", "README.html#goals")),
+				link("SUMMARY", "", "guide/SUMMARY.html"),
+				link("empty", "", "guide/empty.html"),
+				link("Basics", "", "guide/usage.html",
+					item("", "",
+						link("Basics", "basics", "guide/usage.html#basics"))),
+				link("Installation", "installation", "install.html",
+					link("Linux", "linux", "install.html#linux"),
+					link("macOS", "macos", "install.html#macos")),
+			},
+		},
+		{
+			desc: "list",
+			site: Site{
+				Order: OrderList("./install.md", "missing.md", "README.md"),
+			},
+			want: Items{
+				link("Installation", "installation", "install.html",
+					link("Linux", "linux", "install.html#linux"),
+					link("macOS", "macos", "install.html#macos")),
+				link("Welcome", "welcome", "README.html",
+					link("Goals", "goals", "README.html#goals")),
+			},
+		},
+		{
+			desc: "summary",
+			site: Site{
+				Order: OrderSummary([]byte(strings.Join([]string{
+					"# Summary",
+					"",
+					"[Introduction](README.md)",
+					"",
+					"- [Installation](install.md#top)",
+					"  - [Usage](guide/usage.md)",
+					"- Appendix",
+					"  - [Empty](guide/empty.md)",
+					"  - [Missing](missing.md)",
+					"    - [Nested](guide%2Fempty.md)",
+				}, "\n"))),
+				PageTitle: PageTitleFileName,
+			},
+			want: Items{
+				link("README", "", "README.html",
+					link("Welcome", "welcome", "README.html#welcome",
+						link("Goals", "goals", "README.html#goals"))),
+				link("install", "", "install.html",
+					link("Installation", "installation", "install.html#installation",
+						link("Linux", "linux", "install.html#linux"),
+						link("macOS", "macos", "install.html#macos")),
+					link("usage", "", "guide/usage.html",
+						item("", "",
+							link("Basics", "basics", "guide/usage.html#basics")))),
+				item("Appendix", "",
+					link("empty", "", "guide/empty.html"),
+					link("empty", "", "guide/empty.html")),
+			},
+		},
+		{
+			desc: "page url",
+			site: Site{
+				Order: OrderList("install.md"),
+				PageURL: func(path string) string {
+					return "/docs/" + strings.TrimSuffix(path, ".md") + "/"
+				},
+			},
+			want: Items{
+				link("Installation", "installation", "/docs/install/",
+					link("Linux", "linux", "/docs/install/#linux"),
+					link("macOS", "macos", "/docs/install/#macos")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithParserOptions(parser.WithAutoHeadingID()))
+			site := tt.site
+			require.NoError(t, site.ParseFS(md, fsys))
+			assert.Equal(t, &TOC{Items: tt.want}, site.TOC())
+		})
+	}
+}
+
+func TestSite_render(t *testing.T) {
+	t.Parallel()
+
+	var site Site
+	site.Add("install.md", &TOC{
+		Items: Items{
+			item("Installation", "installation",
+				item("Linux", "linux")),
+		},
+	})
+	tree := site.TOC()
+
+	t.Run("html", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, goldmark.DefaultRenderer().Render(&buf, nil, RenderList(tree)))
+		assert.Equal(t, strings.Join([]string{
+			"<ul>",
+			"<li>",
+			`<a href="install.html">Installation</a><ul>`,
+			"<li>",
+			`<a href="install.html#linux">Linux</a></li>`,
+			"</ul>",
+			"</li>",
+			"</ul>",
+		}, "\n")+"\n", buf.String())
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, (&MarkdownRenderer{LinkStyle: LinkReference}).Render(&buf, tree))
+		assert.Equal(t, strings.Join([]string{
+			"- [Installation][installation]",
+			"  - [Linux][linux]",
+			"",
+			"[installation]: install.html",
+			"[linux]: install.html#linux",
+		}, "\n")+"\n", buf.String())
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, site.pages["install.md"].Items[0].URL,
+			"page's table of contents must not be modified")
+	})
+}
@@ -0,0 +1,127 @@
+package toc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// TextExtractor extracts the plain text of an inline node
+// for the title of an item in the table of contents.
+// It's called with the node and the source of the document.
+//
+// For example, the following extracts the text of emoji
+// from the goldmark-emoji extension.
+//
+//	func(n ast.Node, _ []byte) []byte {
+//		return []byte(string(n.(*emojiast.Emoji).Value.Unicode))
+//	}
+//
+// See ExtractText for how to use this.
+type TextExtractor func(n ast.Node, src []byte) []byte
+
+// NoText is a TextExtractor that leaves nodes out of titles.
+func NoText(ast.Node, []byte) []byte {
+	return nil
+}
+
+// _defaultTextExtractors extracts text from nodes
+// that don't hold their text in Text or String children.
+var _defaultTextExtractors = map[ast.NodeKind]TextExtractor{
+	ast.KindAutoLink:          autoLinkText,
+	ast.KindRawHTML:           NoText,
+	east.KindFootnoteLink:     NoText,
+	east.KindFootnoteBacklink: NoText,
+}
+
+func autoLinkText(n ast.Node, src []byte) []byte {
+	return n.(*ast.AutoLink).Label(src)
+}
+
+// ExtractText specifies how Inspect extracts text
+// from inline nodes of the given kind in headings.
+//
+// By default, Inspect uses the text of all Text and String nodes
+// inside a heading, and the following nodes have special handling:
+//
+//   - autolinks are replaced with their URL
+//   - raw HTML is left out
+//   - footnote references are left out
+//
+// Use this to override these, or to extract text from nodes
+// added by other extensions.
+// For example, the following leaves strikethrough text out of titles.
+//
+//	toc.ExtractText(east.KindStrikethrough, toc.NoText)
+//
+// A nil TextExtractor restores the default handling:
+// the text of the node's children.
+//
+// If multiple ExtractText options are provided for the same kind,
+// the last one wins.
+func ExtractText(kind ast.NodeKind, extract TextExtractor) InspectOption {
+	return &extractTextOption{kind: kind, extract: extract}
+}
+
+type extractTextOption struct {
+	kind    ast.NodeKind
+	extract TextExtractor
+}
+
+func (o *extractTextOption) apply(opts *inspectOptions) {
+	if opts.textExtractors == nil {
+		opts.textExtractors = make(textExtractors)
+	}
+	opts.textExtractors[o.kind] = o.extract
+}
+
+func (o *extractTextOption) String() string {
+	return fmt.Sprintf("ExtractText(%v, ...)", o.kind)
+}
+
+// textExtractors holds user-provided TextExtractors.
+// A nil map uses only the defaults.
+type textExtractors map[ast.NodeKind]TextExtractor
+
+// get returns the TextExtractor for the given kind of node,
+// or nil if its text is the text of its children.
+func (m textExtractors) get(kind ast.NodeKind) TextExtractor {
+	if extract, ok := m[kind]; ok {
+		return extract
+	}
+	return _defaultTextExtractors[kind]
+}
+
+func (m textExtractors) text(src []byte, n ast.Node) []byte {
+	var buf bytes.Buffer
+	m.write(src, &buf, n)
+	return buf.Bytes()
+}
+
+func (m textExtractors) write(src []byte, dst io.Writer, n ast.Node) {
+	if extract := m.get(n.Kind()); extract != nil {
+		_, _ = dst.Write(extract(n, src))
+		return
+	}
+
+	switch n := n.(type) {
+	case *ast.Text:
+		_, _ = dst.Write(n.Segment.Value(src))
+		if n.SoftLineBreak() {
+			_, _ = io.WriteString(dst, " ")
+		}
+	case *ast.String:
+		_, _ = dst.Write(n.Value)
+	default:
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			m.write(src, dst, c)
+		}
+	}
+}
+
+func nodeText(src []byte, n ast.Node) []byte {
+	return textExtractors(nil).text(src, n)
+}
@@ -0,0 +1,160 @@
+package toc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestExtractText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		opts []InspectOption
+		want string
+	}{
+		{
+			desc: "plain",
+			give: "# Foo *bar* `baz`",
+			want: "Foo bar baz",
+		},
+		{
+			desc: "footnote",
+			give: "# Foo[^1]\n\n[^1]: Bar",
+			want: "Foo",
+		},
+		{
+			desc: "raw html",
+			give: "# Foo <kbd>Ctrl</kbd>",
+			want: "Foo Ctrl",
+		},
+		{
+			desc: "autolink",
+			give: "# See <https://example.com>",
+			want: "See https://example.com",
+		},
+		{
+			desc: "linkify",
+			give: "# See https://example.com",
+			want: "See https://example.com",
+		},
+		{
+			desc: "image",
+			give: "# ![Logo](logo.png) Project",
+			want: "Logo Project",
+		},
+		{
+			desc: "soft line break",
+			give: "Foo\nbar\n===",
+			want: "Foo bar",
+		},
+		{
+			desc: "custom",
+			give: "# ~~Old~~ New",
+			opts: []InspectOption{
+				ExtractText(east.KindStrikethrough, NoText),
+			},
+			want: " New",
+		},
+		{
+			desc: "custom inline html",
+			give: "# Foo <br>",
+			opts: []InspectOption{
+				ExtractText(ast.KindRawHTML, func(ast.Node, []byte) []byte {
+					return []byte("(html)")
+				}),
+			},
+			want: "Foo (html)",
+		},
+		{
+			desc: "last wins",
+			give: "# ~~Old~~ New",
+			opts: []InspectOption{
+				ExtractText(east.KindStrikethrough, NoText),
+				ExtractText(east.KindStrikethrough, func(ast.Node, []byte) []byte {
+					return []byte("Older")
+				}),
+			},
+			want: "Older New",
+		},
+		{
+			desc: "restore default",
+			give: "# Foo[^1]\n\n[^1]: Bar",
+			opts: []InspectOption{
+				ExtractText(east.KindFootnoteLink, func(n ast.Node, _ []byte) []byte {
+					return []byte("[1]")
+				}),
+				ExtractText(east.KindFootnoteLink, nil),
+			},
+			want: "Foo",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(extension.GFM, extension.Footnote))
+			src := []byte(tt.give + "\n")
+			doc := md.Parser().Parse(text.NewReader(src))
+
+			got, err := Inspect(doc, src, tt.opts...)
+			require.NoError(t, err, "inspect error")
+			require.NotEmpty(t, got.Items)
+			assert.Equal(t, tt.want, string(got.Items[0].Title))
+		})
+	}
+}
+
+func TestExtractText_richTitles(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(extension.Footnote))
+	src := []byte("# Foo[^1] <br> *bar*\n\n[^1]: Baz\n")
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	got, err := Inspect(doc, src,
+		RichTitles(true),
+		ExtractText(ast.KindRawHTML, func(ast.Node, []byte) []byte {
+			return []byte("<newline>")
+		}),
+	)
+	require.NoError(t, err, "inspect error")
+	require.Len(t, got.Items, 1)
+
+	var buf strings.Builder
+	require.NoError(t, md.Renderer().Render(&buf, src, RenderList(got)))
+	assert.Equal(t, "<ul>\n<li>\nFoo &lt;newline&gt; <em>bar</em></li>\n</ul>\n", buf.String())
+}
+
+func TestExtractText_richTitlesOverride(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(extension.Strikethrough))
+	src := []byte("# Foo ~~old~~ `new`\n")
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	got, err := Inspect(doc, src,
+		RichTitles(true),
+		ExtractText(east.KindStrikethrough, NoText),
+		ExtractText(ast.KindCodeSpan, func(ast.Node, []byte) []byte {
+			return []byte("code")
+		}),
+	)
+	require.NoError(t, err, "inspect error")
+	require.Len(t, got.Items, 1)
+
+	var buf strings.Builder
+	require.NoError(t, md.Renderer().Render(&buf, src, RenderList(got)))
+	assert.Equal(t, "<ul>\n<li>\nFoo  code</li>\n</ul>\n", buf.String())
+}
@@ -0,0 +1,187 @@
+package toc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// LabelAttribute specifies an attribute that overrides
+// the title of a heading in the table of contents.
+// Use this to give long headings a short label.
+//
+// Headings only have attributes if the parser was configured with
+// parser.WithHeadingAttribute. For example, the following heading
+// will be listed as "Multi-region" with the default configuration.
+//
+//	## Configuring the pipeline for multi-region clusters {toc-label="Multi-region"}
+//
+// An empty name disables this check.
+//
+// The default is "toc-label".
+func LabelAttribute(name string) InspectOption {
+	return labelAttributeOption(name)
+}
+
+type labelAttributeOption string
+
+func (a labelAttributeOption) apply(opts *inspectOptions) {
+	opts.labelAttribute = string(a)
+}
+
+func (a labelAttributeOption) String() string {
+	return fmt.Sprintf("LabelAttribute(%q)", string(a))
+}
+
+// TitleFunc specifies a function that changes the titles
+// of items in the table of contents.
+// The function is called with each heading included in the table of contents
+// and the title of its item, and returns the new title.
+//
+// For example, the following shortens titles to 20 characters.
+//
+//	toc.TitleFunc(toc.TruncateTitle(20))
+//
+// Titles from LabelAttribute are passed through the function as well.
+//
+// If multiple TitleFunc options are provided,
+// they're applied in the order they were provided.
+//
+// Items whose titles are changed by the function
+// don't get a RichTitle.
+func TitleFunc(fn func(h *ast.Heading, title []byte) []byte) InspectOption {
+	return titleFuncOption(fn)
+}
+
+type titleFuncOption func(*ast.Heading, []byte) []byte
+
+func (f titleFuncOption) apply(opts *inspectOptions) {
+	if f != nil {
+		opts.titleFuncs = append(opts.titleFuncs, f)
+	}
+}
+
+func (f titleFuncOption) String() string {
+	return "TitleFunc(...)"
+}
+
+// TruncateTitle returns a function for TitleFunc
+// that shortens titles longer than n characters
+// to their first n characters followed by an ellipsis.
+//
+//	toc.TruncateTitle(10) // "Configuring the pipeline" => "Configurin…"
+//
+// Spaces before the ellipsis are removed.
+// Titles are left unchanged if n is 0 or less.
+func TruncateTitle(n int) func(*ast.Heading, []byte) []byte {
+	return func(_ *ast.Heading, title []byte) []byte {
+		if n <= 0 || utf8.RuneCount(title) <= n {
+			return title
+		}
+
+		end := 0
+		for range n {
+			_, size := utf8.DecodeRune(title[end:])
+			end += size
+		}
+
+		short := bytes.TrimRight(title[:end], " \t")
+		return append(append([]byte(nil), short...), "…"...)
+	}
+}
+
+// _titlePunctuation is the punctuation removed by TrimTitlePunctuation.
+const _titlePunctuation = ".,:;!?…。、，：；！？"
+
+// TrimTitlePunctuation is a function for TitleFunc
+// that removes trailing punctuation and spaces from titles.
+//
+//	toc.TitleFunc(toc.TrimTitlePunctuation) // "Why?" => "Why"
+//
+// Closing brackets and quotes are left as-is.
+func TrimTitlePunctuation(_ *ast.Heading, title []byte) []byte {
+	return bytes.TrimRightFunc(title, func(r rune) bool {
+		return r == ' ' || r == '\t' || strings.ContainsRune(_titlePunctuation, r)
+	})
+}
+
+// richTitle copies the inline content of the given heading
+// into a new node for use as Item.RichTitle.
+//
+// Returns nil if the heading has no content.
+func richTitle(src []byte, h *ast.Heading, extractors textExtractors) ast.Node {
+	title := ast.NewTextBlock()
+	appendInlines(src, title, h, extractors)
+	if !title.HasChildren() {
+		return nil
+	}
+	return title
+}
+
+// appendInlines appends copies of the inline children of from to dst.
+//
+// Nodes with a user-provided TextExtractor are replaced with its text.
+// Otherwise, code spans, emphasis, and strikethrough are copied as-is.
+// Other nodes are replaced with their text
+// if there's a default TextExtractor for them
+// (e.g. autolinks are replaced with their URL and raw HTML is dropped),
+// or with their contents otherwise.
+// This way, links and images are unwrapped
+// so that the copies may be placed inside another link.
+//
+// Copies of text nodes refer to the same segments of src.
+func appendInlines(src []byte, dst, from ast.Node, extractors textExtractors) {
+	for c := from.FirstChild(); c != nil; c = c.NextSibling() {
+		if extract := extractors[c.Kind()]; extract != nil {
+			if text := extract(c, src); len(text) > 0 {
+				dst.AppendChild(dst, ast.NewString(text))
+			}
+			continue
+		}
+
+		switch c := c.(type) {
+		case *ast.Text:
+			text := ast.NewTextSegment(c.Segment)
+			text.SetRaw(c.IsRaw())
+			text.SetSoftLineBreak(c.SoftLineBreak())
+			dst.AppendChild(dst, text)
+
+		case *ast.String:
+			str := ast.NewString(c.Value)
+			str.SetRaw(c.IsRaw())
+			str.SetCode(c.IsCode())
+			dst.AppendChild(dst, str)
+
+		case *ast.CodeSpan:
+			code := ast.NewCodeSpan()
+			appendInlines(src, code, c, extractors)
+			dst.AppendChild(dst, code)
+
+		case *ast.Emphasis:
+			em := ast.NewEmphasis(c.Level)
+			appendInlines(src, em, c, extractors)
+			dst.AppendChild(dst, em)
+
+		case *east.Strikethrough:
+			del := east.NewStrikethrough()
+			appendInlines(src, del, c, extractors)
+			dst.AppendChild(dst, del)
+
+		default:
+			if extract := extractors.get(c.Kind()); extract != nil {
+				if text := extract(c, src); len(text) > 0 {
+					dst.AppendChild(dst, ast.NewString(text))
+				}
+				break
+			}
+
+			// Links, images, and anything else we don't know about
+			// are replaced with their contents.
+			appendInlines(src, dst, c, extractors)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package toc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestRichTitles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		opts []InspectOption
+
+		wantTitle string // plain title of the first item
+		want      string // rendered list item
+	}{
+		{
+			desc:      "code span",
+			give:      "# The `Render` method",
+			wantTitle: "The Render method",
+			want:      `<a href="#the-render-method">The <code>Render</code> method</a>`,
+		},
+		{
+			desc:      "emphasis",
+			give:      "# *Why* this **matters**",
+			wantTitle: "Why this matters",
+			want:      `<a href="#why-this-matters"><em>Why</em> this <strong>matters</strong></a>`,
+		},
+		{
+			desc:      "strikethrough",
+			give:      "# ~~Old~~ New",
+			wantTitle: "Old New",
+			want:      `<a href="#old-new"><del>Old</del> New</a>`,
+		},
+		{
+			desc:      "link",
+			give:      "# Using [goldmark](https://github.com/yuin/goldmark)",
+			wantTitle: "Using goldmark",
+			want:      `<a href="#using-goldmarkhttpsgithubcomyuingoldmark">Using goldmark</a>`,
+		},
+		{
+			desc:      "link with emphasis",
+			give:      "# See [*this*](#foo)",
+			wantTitle: "See this",
+			want:      `<a href="#see-thisfoo">See <em>this</em></a>`,
+		},
+		{
+			desc:      "image",
+			give:      "# ![Logo](logo.png) Project",
+			wantTitle: "Logo Project",
+			want:      `<a href="#logologopng-project">Logo Project</a>`,
+		},
+		{
+			desc:      "autolink",
+			give:      "# Visit <https://example.com>",
+			wantTitle: "Visit https://example.com",
+			want:      `<a href="#visit-httpsexamplecom">Visit https://example.com</a>`,
+		},
+		{
+			desc:      "raw html",
+			give:      "# Foo <span>bar</span>",
+			wantTitle: "Foo bar",
+			want:      `<a href="#foo-spanbarspan">Foo bar</a>`,
+		},
+		{
+			desc:      "escaped",
+			give:      `# \*Not\* emphasis & <stuff>`,
+			wantTitle: "*Not* emphasis & ",
+			want:      `<a href="#not-emphasis--stuff">*Not* emphasis &amp; </a>`,
+		},
+		{
+			desc:      "numbered",
+			give:      "# The `toc` package",
+			opts:      []InspectOption{Numbered(true)},
+			wantTitle: "The toc package",
+			want:      `<a href="#the-toc-package">1 The <code>toc</code> package</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(
+				goldmark.WithExtensions(extension.Strikethrough),
+				goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			)
+
+			src := []byte(tt.give + "\n")
+			doc := md.Parser().Parse(text.NewReader(src))
+
+			opts := append([]InspectOption{RichTitles(true)}, tt.opts...)
+			got, err := Inspect(doc, src, opts...)
+			require.NoError(t, err, "inspect error")
+			require.Len(t, got.Items, 1)
+			assert.Equal(t, tt.wantTitle, string(got.Items[0].Title))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Renderer().Render(&buf, src, RenderList(got)))
+			want := strings.Join([]string{
+				"<ul>",
+				"<li>",
+				tt.want + "</li>",
+				"</ul>",
+				"",
+			}, "\n")
+			assert.Equal(t, want, buf.String())
+		})
+	}
+}
+
+func TestRichTitles_disabled(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("# The `Render` method\n")
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+	).Parse(text.NewReader(src))
+
+	got, err := Inspect(doc, src)
+	require.NoError(t, err)
+	require.Len(t, got.Items, 1)
+	assert.Nil(t, got.Items[0].RichTitle)
+}
+
+func TestLabelAttribute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give []string
+		opts []InspectOption
+		want Items
+	}{
+		{
+			desc: "default",
+			give: []string{
+				`# Configuring the pipeline for multi-region clusters {toc-label="Multi-region"}`,
+				"## Foo",
+			},
+			want: Items{
+				item("Multi-region", "configuring-the-pipeline-for-multi-region-clusters",
+					item("Foo", "foo")),
+			},
+		},
+		{
+			desc: "custom attribute",
+			give: []string{
+				`# Foo {short="F"}`,
+				`# Bar {toc-label="B"}`,
+			},
+			opts: []InspectOption{LabelAttribute("short")},
+			want: Items{
+				item("F", "foo"),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "disabled",
+			give: []string{
+				`# Foo {toc-label="F"}`,
+			},
+			opts: []InspectOption{LabelAttribute("")},
+			want: Items{
+				item("Foo", "foo"),
+			},
+		},
+		{
+			desc: "title func",
+			give: []string{
+				`# Foo {toc-label="Why?"}`,
+				"# Bar!",
+			},
+			opts: []InspectOption{TitleFunc(TrimTitlePunctuation)},
+			want: Items{
+				item("Why", "foo"),
+				item("Bar", "bar"),
+			},
+		},
+		{
+			desc: "multiple title funcs",
+			give: []string{
+				"# Frequently asked questions?",
+			},
+			opts: []InspectOption{
+				TitleFunc(TrimTitlePunctuation),
+				TitleFunc(TruncateTitle(10)),
+			},
+			want: Items{
+				item("Frequently…", "frequently-asked-questions"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(strings.Join(tt.give, "\n") + "\n")
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+				parser.WithHeadingAttribute(),
+			).Parse(text.NewReader(src))
+
+			got, err := Inspect(doc, src, tt.opts...)
+			require.NoError(t, err, "inspect error")
+			assert.Equal(t, &TOC{Items: tt.want}, got)
+		})
+	}
+}
+
+func TestLabelAttribute_richTitles(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("# The `Render` method {toc-label=\"Render\"}\n# The `Parse` method\n")
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithHeadingAttribute(),
+	).Parse(text.NewReader(src))
+
+	got, err := Inspect(doc, src, RichTitles(true))
+	require.NoError(t, err, "inspect error")
+	require.Len(t, got.Items, 2)
+
+	assert.Nil(t, got.Items[0].RichTitle, "labeled items must not have a rich title")
+	assert.NotNil(t, got.Items[1].RichTitle)
+}
+
+func TestTruncateTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		n    int
+		give string
+		want string
+	}{
+		{desc: "short", n: 10, give: "Foo", want: "Foo"},
+		{desc: "exact", n: 3, give: "Foo", want: "Foo"},
+		{desc: "long", n: 3, give: "Foobar", want: "Foo…"},
+		{desc: "trailing space", n: 4, give: "Foo bar", want: "Foo…"},
+		{desc: "multibyte", n: 2, give: "日本語", want: "日本…"},
+		{desc: "zero", n: 0, give: "Foobar", want: "Foobar"},
+		{desc: "negative", n: -1, give: "Foobar", want: "Foobar"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			give := []byte(tt.give)
+			got := TruncateTitle(tt.n)(nil, give)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.give, string(give), "input must not be modified")
+		})
+	}
+}
+
+func TestTrimTitlePunctuation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "Foo", want: "Foo"},
+		{give: "Why?", want: "Why"},
+		{give: "Notes:", want: "Notes"},
+		{give: "Wait... ", want: "Wait"},
+		{give: "Foo (bar)", want: "Foo (bar)"},
+		{give: `Say "hi"`, want: `Say "hi"`},
+		{give: "なぜ？", want: "なぜ"},
+		{give: "?!", want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			got := TrimTitlePunctuation(nil, []byte(tt.give))
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
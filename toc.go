@@ -1,5 +1,7 @@
 package toc
 
+import "github.com/yuin/goldmark/ast"
+
 // TOC is the table of contents. It's the top-level object under which the
 // rest of the table of contents resides.
 type TOC struct {
@@ -27,6 +29,77 @@ type Item struct {
 	// but they weren't.
 	ID []byte
 
+	// URL is the link destination for this item,
+	// e.g. "install.html#linux".
+	// If this is empty, items with IDs link to "#" followed by the ID.
+	//
+	// Set this to link items to other pages or websites,
+	// e.g. for items merged from other tables of contents.
+	//
+	// Inspect does not set this.
+	// It's set for items in tables of contents built by Site,
+	// which refer to headings in other documents.
+	URL []byte
+
+	// RichTitle holds the inline content of the heading
+	// that this item refers to as its children,
+	// preserving code spans, emphasis, and strikethrough.
+	// Links and images in the heading are replaced with their contents.
+	//
+	// Text in RichTitle refers to the source of the document,
+	// so it must be rendered with the same source.
+	//
+	// This is set only if Inspect was called with RichTitles(true).
+	// ListRenderer renders RichTitle in place of Title if it's set.
+	RichTitle ast.Node
+
+	// Number is the section number of this item.
+	// For example, []int{3, 2, 1} for section 3.2.1.
+	//
+	// This is set only if Inspect was called with Numbered(true).
+	// Items without titles don't have numbers.
+	Number []int
+
+	// Level is the level of the heading that this item refers to,
+	// e.g. 2 for "## Foo".
+	// For placeholders, this is the level that the item stands for.
+	//
+	// Level is the heading's level in the document,
+	// so it may differ from the item's depth in the table of contents
+	// if the table of contents was normalized or compacted.
+	//
+	// This is set only if Inspect was called with Metadata(true).
+	Level int
+
+	// Placeholder reports whether this item was created
+	// to hold items for headings that skipped a level,
+	// instead of from a heading.
+	// For example, given the following,
+	// the item holding "Bar" is a placeholder:
+	//
+	//	# Foo
+	//	### Bar
+	//
+	// This is set only if Inspect was called with Metadata(true).
+	Placeholder bool
+
+	// Line is the 1-indexed line number of the heading
+	// that this item refers to in the source.
+	// This is 0 for placeholders
+	// and for headings without a known position.
+	//
+	// This is set only if Inspect was called with Metadata(true).
+	Line int
+
+	// Heading is the heading that this item refers to.
+	// This is nil for placeholders.
+	//
+	// Headings found inside raw HTML (see HTMLHeadings)
+	// are not part of the document.
+	//
+	// This is set only if Inspect was called with Metadata(true).
+	Heading *ast.Heading
+
 	// Items references children of this item.
 	//
 	// For a heading at level 3, Items, contains the headings at level 4
@@ -36,3 +109,36 @@ type Item struct {
 
 // Items is a list of items in a table of contents.
 type Items []*Item
+
+// LinkFunc returns the link destination for an item
+// in a table of contents,
+// or nil if the item should not be a link.
+//
+// For example, the following links items to headings
+// on another page:
+//
+//	func(item *toc.Item) []byte {
+//		if len(item.ID) == 0 {
+//			return nil
+//		}
+//		return append([]byte("/guide/#"), item.ID...)
+//	}
+type LinkFunc func(item *Item) []byte
+
+// itemLink returns the link destination for an item.
+//
+// If fn is set, it decides the destination.
+// Otherwise, items link to their URL if they have one,
+// or to their ID placed after the base URL and "#".
+func itemLink(item *Item, baseURL string, fn LinkFunc) []byte {
+	switch {
+	case fn != nil:
+		return fn(item)
+	case len(item.URL) > 0:
+		return item.URL
+	case len(item.ID) > 0:
+		return append([]byte(baseURL+"#"), item.ID...)
+	default:
+		return nil
+	}
+}
@@ -1,9 +1,13 @@
 package toc
 
 import (
+	"bytes"
+	"fmt"
+
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
 const (
@@ -14,8 +18,168 @@ const (
 	_maxTitleDepth     = 6
 )
 
-// Transformer is a Goldmark AST transformer adds a TOC to the top of a
-// Markdown document.
+// Position specifies where the Transformer places the table of contents
+// if the document does not have a marker for it.
+type Position int
+
+const (
+	// PositionTop places the table of contents
+	// at the top of the document.
+	//
+	// This is the default.
+	PositionTop Position = iota
+
+	// PositionBottom places the table of contents
+	// at the end of the document.
+	PositionBottom
+
+	// PositionNone does not place the table of contents anywhere.
+	//
+	// Use this with Marker to add a table of contents
+	// only to documents that ask for it.
+	PositionNone
+
+	// PositionAfterTitle places the table of contents
+	// right after the leading title of the document.
+	// See OmitLeadingTitle for what counts as a title.
+	//
+	// Documents without a title get the table of contents
+	// at the top instead.
+	PositionAfterTitle
+
+	// PositionBeforeFirstHeading places the table of contents
+	// right before the first top-level heading of the document,
+	// after any introductory text.
+	//
+	// Documents without a top-level heading
+	// get the table of contents at the top instead.
+	PositionBeforeFirstHeading
+
+	// PositionAfterFirstHeading places the table of contents
+	// right after the first top-level heading of the document.
+	//
+	// Documents without a top-level heading
+	// get the table of contents at the top instead.
+	PositionAfterFirstHeading
+
+	// PositionAfterFirstParagraph places the table of contents
+	// right after the first top-level paragraph of the document.
+	//
+	// Documents without a top-level paragraph
+	// get the table of contents at the top instead.
+	PositionAfterFirstParagraph
+)
+
+var _positionNames = map[Position]string{
+	PositionTop:                 "top",
+	PositionBottom:              "bottom",
+	PositionNone:                "none",
+	PositionAfterTitle:          "after-title",
+	PositionBeforeFirstHeading:  "before-first-heading",
+	PositionAfterFirstHeading:   "after-first-heading",
+	PositionAfterFirstParagraph: "after-first-paragraph",
+}
+
+// LeadingTitle specifies what the Transformer does
+// with the leading title of a document.
+// See OmitLeadingTitle for what counts as a title.
+type LeadingTitle int
+
+const (
+	// LeadingTitleKeep includes the title in the table of contents,
+	// with all other items nested under it.
+	//
+	// This is the default.
+	LeadingTitleKeep LeadingTitle = iota
+
+	// LeadingTitleOmit leaves the title out of the table of contents,
+	// and promotes the items under it.
+	LeadingTitleOmit
+
+	// LeadingTitleAsTitle leaves the title out of the table of contents
+	// like LeadingTitleOmit, and uses the title heading itself
+	// as the title of the table of contents:
+	// the list is placed right after it
+	// instead of under a heading with Transformer.Title.
+	// Position and Anchor are not used in this case.
+	// If the document has a Marker, the list is placed there instead,
+	// still without a heading of its own.
+	//
+	// If the title was found in raw HTML (see HTMLHeadings)
+	// and there is no marker,
+	// its text is used in place of Transformer.Title instead.
+	LeadingTitleAsTitle
+)
+
+var _leadingTitleNames = map[LeadingTitle]string{
+	LeadingTitleKeep:    "keep",
+	LeadingTitleOmit:    "omit",
+	LeadingTitleAsTitle: "title",
+}
+
+// String returns the name of the option,
+// e.g. "omit" for LeadingTitleOmit.
+func (l LeadingTitle) String() string {
+	if name, ok := _leadingTitleNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LeadingTitle(%d)", int(l))
+}
+
+// MarshalText implements encoding.TextMarshaler for LeadingTitle.
+func (l LeadingTitle) MarshalText() ([]byte, error) {
+	if _, ok := _leadingTitleNames[l]; !ok {
+		return nil, fmt.Errorf("unknown leading title option %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for LeadingTitle.
+// It accepts the names returned by String, e.g. "keep" or "omit".
+func (l *LeadingTitle) UnmarshalText(b []byte) error {
+	for opt, name := range _leadingTitleNames {
+		if string(b) == name {
+			*l = opt
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown leading title option %q", b)
+}
+
+// String returns the name of the position,
+// e.g. "top" for PositionTop.
+func (p Position) String() string {
+	if name, ok := _positionNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Position(%d)", int(p))
+}
+
+// MarshalText implements encoding.TextMarshaler for Position.
+func (p Position) MarshalText() ([]byte, error) {
+	if _, ok := _positionNames[p]; !ok {
+		return nil, fmt.Errorf("unknown position %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for Position.
+// It accepts the names returned by String, e.g. "top" or "bottom".
+func (p *Position) UnmarshalText(b []byte) error {
+	for pos, name := range _positionNames {
+		if string(b) == name {
+			*p = pos
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown position %q", b)
+}
+
+// Transformer is a Goldmark AST transformer adds a TOC to a Markdown
+// document. By default, the TOC is added to the top of the document.
+//
+// The Transformer also stores the TOC in the parser.Context.
+// Use FromContext to retrieve it.
 //
 // To use this, either install the Extender on the goldmark.Markdown object,
 // or install the AST transformer on the Markdown parser like so.
@@ -48,6 +212,21 @@ type Transformer struct {
 	// See the documentation for MaxDepth for more information.
 	MaxDepth int
 
+	// RelativeMinDepth is the minimum depth of the table of contents,
+	// counted from the shallowest heading in the document.
+	// See the documentation for RelativeMinDepth for more information.
+	RelativeMinDepth int
+
+	// RelativeMaxDepth is the maximum depth of the table of contents,
+	// counted from the shallowest heading in the document.
+	// See the documentation for RelativeMaxDepth for more information.
+	RelativeMaxDepth int
+
+	// Normalize specifies whether the table of contents should be shifted
+	// so that the shallowest heading in it is at the top level.
+	// See the documentation for Normalize for more information.
+	Normalize bool
+
 	// ListID is the id for the list of TOC items rendered in the HTML.
 	//
 	// For example, if ListID is "toc", the table of contents will be
@@ -71,10 +250,244 @@ type Transformer struct {
 	// from the Goldmark Parser.
 	TitleID string
 
+	// BaseURL is placed before links to headings
+	// in the table of contents.
+	// See ListRenderer.BaseURL for more information.
+	BaseURL string
+
+	// LinkFunc, if set, decides the link destination
+	// for each item in the table of contents.
+	// See ListRenderer.LinkFunc for more information.
+	LinkFunc LinkFunc
+
 	// Compact controls whether empty items should be removed
 	// from the table of contents.
 	// See the documentation for Compact for more information.
 	Compact bool
+
+	// MinItems is the minimum number of headings that
+	// the table of contents must list to be added to the document.
+	// Only items with titles are counted,
+	// after filtering and compaction.
+	//
+	// Documents with fewer headings don't get a table of contents,
+	// and FromContext returns nil for them
+	// so that templates may skip the table of contents too.
+	// Chapter tables of contents are still added (see ChapterLevel);
+	// use ChapterMinItems to limit those.
+	//
+	// Documents without headings never get a table of contents.
+	MinItems int
+
+	// Marker is a placeholder that marks where the table of contents
+	// should be placed in the document.
+	//
+	// The first paragraph or HTML block in the document
+	// whose text is exactly the Marker
+	// (ignoring leading and trailing whitespace)
+	// is replaced with the table of contents.
+	// For example, with the Marker "[TOC]" or "<!-- toc -->",
+	// the following paragraph or HTML comment
+	// will be replaced with the table of contents:
+	//
+	//	[TOC]
+	//
+	//	<!-- toc -->
+	//
+	// The marker is removed even if the document
+	// doesn't get a table of contents,
+	// e.g. because it has no headings.
+	//
+	// If Marker is empty, or the document does not contain it,
+	// the table of contents is placed according to Position.
+	Marker string
+
+	// Position specifies where the table of contents is placed
+	// if the document does not contain a Marker.
+	//
+	// Defaults to PositionTop.
+	Position Position
+
+	// Anchor, if set, picks where the table of contents is placed
+	// if the document does not contain a Marker.
+	// The table of contents is placed right after the node it returns,
+	// which must be part of the document.
+	//
+	// For example, the following places the table of contents
+	// after the first block quote in the document.
+	//
+	//	func(doc *ast.Document) ast.Node {
+	//		for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+	//			if n.Kind() == ast.KindBlockquote {
+	//				return n
+	//			}
+	//		}
+	//		return nil
+	//	}
+	//
+	// If Anchor returns nil, the table of contents is placed
+	// according to Position.
+	Anchor func(doc *ast.Document) ast.Node
+
+	// LeadingTitle specifies what to do with the leading title
+	// of the document, e.g. a single <h1> at the top.
+	//
+	// Defaults to LeadingTitleKeep.
+	LeadingTitle LeadingTitle
+
+	// Wrap specifies whether the title and list of the table of contents
+	// should be wrapped in a Node.
+	//
+	// Goldmark cannot render a Node unless a renderer for it is installed.
+	// Install HTMLRenderer on the goldmark renderer if you set this,
+	// or use the Extender with Extender.Nav.
+	Wrap bool
+
+	// Filter, if set, decides whether a heading
+	// should be included in the table of contents.
+	// See the documentation for Filter for more information.
+	Filter func(h *ast.Heading, src []byte) bool
+
+	// IgnoreClass is the class that marks a heading
+	// to be left out of the table of contents.
+	// See the documentation for IgnoreClass for more information.
+	//
+	// Defaults to "notoc". Set this to "-" to disable this check.
+	IgnoreClass string
+
+	// IgnoreAttribute is the attribute that marks a heading
+	// to be left out of the table of contents if it's set to "false".
+	// See the documentation for IgnoreAttribute for more information.
+	//
+	// Defaults to "data-toc". Set this to "-" to disable this check.
+	IgnoreAttribute string
+
+	// IgnoreComment is the text of an HTML comment that marks
+	// the heading right after it to be left out of the table of contents.
+	// See the documentation for IgnoreComment for more information.
+	//
+	// Defaults to "toc:ignore". Set this to "-" to disable this check.
+	IgnoreComment string
+
+	// Numbered specifies whether items in the table of contents
+	// should be numbered, e.g. "3.2.1 Encoding".
+	// See the documentation for Numbered for more information.
+	Numbered bool
+
+	// NumberFormat formats section numbers.
+	// This has no effect unless Numbered is set.
+	//
+	// Defaults to FormatNumber(Decimal), which renders "3.2.1".
+	NumberFormat NumberFormat
+
+	// NumberHeadings specifies whether the headings in the document
+	// should also be prefixed with their section numbers.
+	// This has no effect unless Numbered is set.
+	//
+	// IDs of headings are not affected by this.
+	NumberHeadings bool
+
+	// RichTitles specifies whether items in the table of contents
+	// should keep the inline formatting of their headings,
+	// e.g. code spans and emphasis.
+	// See the documentation for RichTitles for more information.
+	RichTitles bool
+
+	// TextExtractors specifies how to extract the text of titles
+	// from inline nodes of different kinds in headings.
+	// See the documentation for ExtractText for more information.
+	TextExtractors map[ast.NodeKind]TextExtractor
+
+	// LabelAttribute is the heading attribute
+	// that overrides the title of a heading in the table of contents.
+	// See the documentation for LabelAttribute for more information.
+	//
+	// Defaults to "toc-label" if unspecified.
+	LabelAttribute string
+
+	// TitleFunc, if set, changes the titles
+	// of items in the table of contents.
+	// See the documentation for TitleFunc for more information.
+	TitleFunc func(h *ast.Heading, title []byte) []byte
+
+	// HTMLHeadings specifies whether headings written in raw HTML,
+	// e.g. <h2 id="foo">Foo</h2>, should be included
+	// in the table of contents.
+	// See the documentation for HTMLHeadings for more information.
+	HTMLHeadings bool
+
+	// TopLevelOnly specifies whether only headings at the top level
+	// of the document should be included in the table of contents.
+	// See the documentation for TopLevelOnly for more information.
+	TopLevelOnly bool
+
+	// ExcludeContainers lists kinds of block nodes,
+	// e.g. ast.KindBlockquote, whose headings should be left out
+	// of the table of contents.
+	// See the documentation for ExcludeContainers for more information.
+	ExcludeContainers []ast.NodeKind
+
+	// ChapterLevel, if set, adds a table of contents
+	// for each chapter of the document: a heading at this level.
+	// The chapter's table of contents lists the headings under it,
+	// and is placed right after the heading.
+	//
+	// For example, with ChapterLevel 1, the following:
+	//
+	//	# Installation
+	//	## Linux
+	//	## macOS
+	//
+	// Gets a table of contents with "Linux" and "macOS"
+	// right after "Installation".
+	//
+	// Chapter tables of contents are added in addition to the table
+	// of contents for the whole document.
+	// Use PositionNone to add only chapter tables of contents.
+	// If Wrap is set, each one is wrapped in its own Node.
+	//
+	// Chapter tables of contents are not added if this is 0 or less.
+	ChapterLevel int
+
+	// ChapterTitle is the title placed before
+	// each chapter's table of contents.
+	// This is rendered as a paragraph, not a heading,
+	// so that it doesn't become part of the document outline.
+	//
+	// Chapter tables of contents don't have a title if this is empty.
+	ChapterTitle string
+
+	// ChapterMaxDepth is the maximum depth of
+	// each chapter's table of contents,
+	// counted from the headings right under the chapter.
+	// For example, with ChapterMaxDepth 1, only headings one level
+	// below the chapter's heading are listed.
+	//
+	// A value of 0 or less will result in no limit.
+	ChapterMaxDepth int
+
+	// ChapterMinItems is the minimum number of items
+	// that a chapter's table of contents must have to be added.
+	// For example, with ChapterMinItems 2, chapters with
+	// only one subsection don't get a table of contents.
+	//
+	// Chapters with no subsections never get a table of contents.
+	ChapterMinItems int
+
+	// Configure, if set, is called for each document
+	// before its table of contents is generated.
+	//
+	// It receives a copy of this Transformer,
+	// which it may modify to change the table of contents
+	// for only that document.
+	// For example, it may change the Title or MaxDepth
+	// based on the document's front matter.
+	// If it returns false, no table of contents
+	// is added to the document.
+	//
+	// See ConfigureFromMeta to read this configuration
+	// from the document's metadata.
+	Configure func(ctx parser.Context, t *Transformer) bool
 }
 
 var _ parser.ASTTransformer = (*Transformer)(nil) // interface compliance
@@ -84,26 +497,114 @@ var _ parser.ASTTransformer = (*Transformer)(nil) // interface compliance
 // Errors encountered while transforming are ignored. For more fine-grained
 // control, use Inspect and transform the document manually.
 func (t *Transformer) Transform(doc *ast.Document, reader text.Reader, ctx parser.Context) {
-	toc, err := Inspect(doc, reader.Source(), MinDepth(t.MinDepth), MaxDepth(t.MaxDepth), Compact(t.Compact))
+	enabled := true
+	if t.Configure != nil {
+		cfg := *t
+		cfg.Configure = nil
+		enabled = t.Configure(ctx, &cfg)
+		t = &cfg
+	}
+
+	// The marker is removed even if no table of contents is added
+	// so that it doesn't show up in the document.
+	marker := findMarker(doc, reader.Source(), t.Marker)
+	if marker != nil {
+		defer marker.Parent().RemoveChild(marker.Parent(), marker)
+	}
+	if !enabled {
+		return
+	}
+
+	opts := t.inspectOptions()
+
+	var leadingTitle *ast.Heading
+	if t.Position == PositionAfterTitle || t.LeadingTitle == LeadingTitleAsTitle {
+		opts = append(opts, onLeadingTitleOption(func(h *ast.Heading) {
+			leadingTitle = h
+		}))
+	}
+
+	// Headings that items were built from,
+	// if we need their numbers.
+	var headings map[*Item]*ast.Heading
+	if t.Numbered && (t.NumberHeadings || t.ChapterLevel > 0) {
+		headings = make(map[*Item]*ast.Heading)
+		opts = append(opts, onItemOption(func(item *Item, h *ast.Heading) {
+			headings[item] = h
+		}))
+	}
+
+	toc, err := Inspect(doc, reader.Source(), opts...)
 	if err != nil {
 		// There are currently no scenarios under which Inspect
 		// returns an error but we have to account for it anyway.
 		return
 	}
+	// Documents with too few headings don't get a table of contents,
+	// but their chapters may still get their own.
+	tooFew := t.MinItems > 0 && countTitled(toc.Items) < t.MinItems
+	if !tooFew {
+		ctx.Set(ContextKey, toc)
+	}
 
 	// Don't add anything for documents with no headings.
 	if len(toc.Items) == 0 {
 		return
 	}
 
-	listNode := RenderList(toc)
+	listRenderer := t.listRenderer()
+	if t.ChapterLevel > 0 {
+		// This must happen before headings are numbered
+		// so that the numbers don't become part of the titles.
+		t.addChapterTOCs(doc, reader.Source(), headings)
+	}
+
+	for item, heading := range headings {
+		if !t.NumberHeadings || len(item.Number) == 0 {
+			continue
+		}
+
+		number := ast.NewString([]byte(listRenderer.formatNumber(item.Number) + " "))
+		number.SetRaw(true)
+		heading.InsertBefore(heading, heading.FirstChild(), number)
+	}
+
+	if tooFew {
+		return
+	}
+
+	listNode := listRenderer.Render(toc)
 	if id := t.ListID; len(id) > 0 {
 		listNode.SetAttributeString("id", []byte(id))
 	}
 
-	doc.InsertBefore(doc, doc.FirstChild(), listNode)
+	// The document's title already heads the table of contents,
+	// so the list goes right after it, or at the marker,
+	// without a title of its own.
+	// Headings from raw HTML are not part of the document,
+	// so without a marker, those are copied into a title below instead.
+	if t.LeadingTitle == LeadingTitleAsTitle && leadingTitle != nil &&
+		(marker != nil || isChild(leadingTitle.Parent(), leadingTitle)) {
+		nodes := []ast.Node{listNode}
+		if t.Wrap {
+			node := NewNode(toc)
+			node.AppendChild(node, listNode)
+			nodes = []ast.Node{node}
+		}
+		if marker != nil {
+			insertBefore(marker, nodes)
+		} else {
+			insertAfter(leadingTitle, nodes)
+		}
+		return
+	}
 
 	title := t.Title
+	if t.LeadingTitle == LeadingTitleAsTitle && leadingTitle != nil {
+		title = string(util.UnescapePunctuations(
+			textExtractors(t.TextExtractors).text(reader.Source(), leadingTitle),
+		))
+	}
 	if len(title) == 0 {
 		title = _defaultTitle
 	}
@@ -126,5 +627,223 @@ func (t *Transformer) Transform(doc *ast.Document, reader text.Reader, ctx parse
 		heading.SetAttributeString("id", id)
 	}
 
-	doc.InsertBefore(doc, doc.FirstChild(), heading)
+	nodes := []ast.Node{heading, listNode}
+	if t.Wrap {
+		node := NewNode(toc)
+		node.AppendChild(node, heading)
+		node.AppendChild(node, listNode)
+		nodes = []ast.Node{node}
+	}
+
+	if marker != nil {
+		insertBefore(marker, nodes)
+		return
+	}
+
+	t.place(doc, nodes, leadingTitle)
+}
+
+// place adds the given nodes to a document that doesn't have a marker,
+// according to Anchor and Position.
+func (t *Transformer) place(doc *ast.Document, nodes []ast.Node, leadingTitle *ast.Heading) {
+	if t.Anchor != nil {
+		if anchor := t.Anchor(doc); anchor != nil {
+			insertAfter(anchor, nodes)
+			return
+		}
+	}
+
+	switch t.Position {
+	case PositionTop:
+		// Handled below.
+
+	case PositionBottom:
+		for _, n := range nodes {
+			doc.AppendChild(doc, n)
+		}
+		return
+
+	case PositionAfterTitle:
+		// Headings from raw HTML are not part of the document.
+		if leadingTitle != nil && isChild(leadingTitle.Parent(), leadingTitle) {
+			insertAfter(leadingTitle, nodes)
+			return
+		}
+
+	case PositionBeforeFirstHeading:
+		if h := firstChildOfKind(doc, ast.KindHeading); h != nil {
+			insertBefore(h, nodes)
+			return
+		}
+
+	case PositionAfterFirstHeading:
+		if h := firstChildOfKind(doc, ast.KindHeading); h != nil {
+			insertAfter(h, nodes)
+			return
+		}
+
+	case PositionAfterFirstParagraph:
+		if p := firstChildOfKind(doc, ast.KindParagraph); p != nil {
+			insertAfter(p, nodes)
+			return
+		}
+
+	default:
+		// PositionNone and unknown positions.
+		return
+	}
+
+	// PositionTop, or a position whose target wasn't found.
+	first := doc.FirstChild()
+	for _, n := range nodes {
+		doc.InsertBefore(doc, first, n)
+	}
+}
+
+func (t *Transformer) inspectOptions() []InspectOption {
+	opts := []InspectOption{
+		MinDepth(t.MinDepth),
+		MaxDepth(t.MaxDepth),
+		RelativeMinDepth(t.RelativeMinDepth),
+		RelativeMaxDepth(t.RelativeMaxDepth),
+		Normalize(t.Normalize),
+		OmitLeadingTitle(t.LeadingTitle == LeadingTitleOmit || t.LeadingTitle == LeadingTitleAsTitle),
+		Compact(t.Compact),
+		Numbered(t.Numbered),
+		RichTitles(t.RichTitles),
+		HTMLHeadings(t.HTMLHeadings),
+		TopLevelOnly(t.TopLevelOnly),
+		ExcludeContainers(t.ExcludeContainers...),
+	}
+	if t.Filter != nil {
+		opts = append(opts, Filter(t.Filter))
+	}
+	if len(t.IgnoreClass) > 0 {
+		opts = append(opts, IgnoreClass(ignoreValue(t.IgnoreClass)))
+	}
+	if len(t.IgnoreAttribute) > 0 {
+		opts = append(opts, IgnoreAttribute(ignoreValue(t.IgnoreAttribute)))
+	}
+	if len(t.IgnoreComment) > 0 {
+		opts = append(opts, IgnoreComment(ignoreValue(t.IgnoreComment)))
+	}
+	if len(t.LabelAttribute) > 0 {
+		opts = append(opts, LabelAttribute(t.LabelAttribute))
+	}
+	if t.TitleFunc != nil {
+		opts = append(opts, TitleFunc(t.TitleFunc))
+	}
+	for kind, extract := range t.TextExtractors {
+		opts = append(opts, ExtractText(kind, extract))
+	}
+	return opts
+}
+
+// _ignoreDisabled is the value of IgnoreClass, IgnoreAttribute,
+// and IgnoreComment that disables the corresponding check.
+const _ignoreDisabled = "-"
+
+// ignoreValue returns the value of the Ignore* option
+// for the given Ignore* field of a Transformer.
+func ignoreValue(v string) string {
+	if v == _ignoreDisabled {
+		return ""
+	}
+	return v
+}
+
+// insertBefore inserts the given nodes before ref.
+func insertBefore(ref ast.Node, nodes []ast.Node) {
+	parent := ref.Parent()
+	for _, n := range nodes {
+		parent.InsertBefore(parent, ref, n)
+	}
+}
+
+// insertAfter inserts the given nodes after ref.
+func insertAfter(ref ast.Node, nodes []ast.Node) {
+	parent := ref.Parent()
+	for _, n := range nodes {
+		parent.InsertAfter(parent, ref, n)
+		ref = n
+	}
+}
+
+// firstChildOfKind returns the first child of the given node
+// of the given kind, or nil if there isn't one.
+func firstChildOfKind(parent ast.Node, kind ast.NodeKind) ast.Node {
+	for c := parent.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Kind() == kind {
+			return c
+		}
+	}
+	return nil
+}
+
+// isChild reports whether n is a child of parent.
+func isChild(parent, n ast.Node) bool {
+	if parent == nil {
+		return false
+	}
+	for c := parent.FirstChild(); c != nil; c = c.NextSibling() {
+		if c == n {
+			return true
+		}
+	}
+	return false
+}
+
+// findMarker finds the first paragraph or HTML block in the document
+// that holds only the given marker text.
+//
+// Returns nil if a marker was not found or if the marker is empty.
+func findMarker(doc ast.Node, src []byte, marker string) ast.Node {
+	want := bytes.TrimSpace([]byte(marker))
+	if len(want) == 0 {
+		return nil
+	}
+
+	var found ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n.(type) {
+		case *ast.Paragraph, *ast.HTMLBlock:
+			if bytes.Equal(bytes.TrimSpace(n.Lines().Value(src)), want) {
+				found = n
+				return ast.WalkStop, nil
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
+		if n.Type() == ast.TypeInline {
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// countTitled returns the number of items with titles
+// in the given tree of items.
+func countTitled(items Items) int {
+	var count int
+	for item := range items.All() {
+		if len(item.Title) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// listRenderer returns the ListRenderer
+// for tables of contents built by this Transformer.
+func (t *Transformer) listRenderer() ListRenderer {
+	return ListRenderer{
+		NumberFormat: t.NumberFormat,
+		BaseURL:      t.BaseURL,
+		LinkFunc:     t.LinkFunc,
+	}
 }
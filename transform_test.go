@@ -136,3 +136,453 @@ func TestTransformerWithTitleDepth(t *testing.T) {
 		})
 	}
 }
+
+func TestPosition_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give Position
+		want string
+	}{
+		{give: PositionTop, want: "top"},
+		{give: PositionBottom, want: "bottom"},
+		{give: PositionNone, want: "none"},
+		{give: PositionAfterTitle, want: "after-title"},
+		{give: PositionBeforeFirstHeading, want: "before-first-heading"},
+		{give: PositionAfterFirstHeading, want: "after-first-heading"},
+		{give: PositionAfterFirstParagraph, want: "after-first-paragraph"},
+		{give: Position(42), want: "Position(42)"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.give.String())
+		})
+	}
+}
+
+func TestPosition_TextRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for give := range _positionNames {
+		give := give
+		t.Run(give.String(), func(t *testing.T) {
+			t.Parallel()
+
+			text, err := give.MarshalText()
+			require.NoError(t, err)
+
+			var got Position
+			require.NoError(t, got.UnmarshalText(text))
+			assert.Equal(t, give, got)
+		})
+	}
+}
+
+func TestPosition_UnmarshalTextError(t *testing.T) {
+	t.Parallel()
+
+	var p Position
+	assert.ErrorContains(t, p.UnmarshalText([]byte("sideways")), `unknown position "sideways"`)
+
+	_, err := Position(42).MarshalText()
+	assert.ErrorContains(t, err, "unknown position 42")
+}
+
+func TestLeadingTitle_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give LeadingTitle
+		want string
+	}{
+		{give: LeadingTitleKeep, want: "keep"},
+		{give: LeadingTitleOmit, want: "omit"},
+		{give: LeadingTitleAsTitle, want: "title"},
+		{give: LeadingTitle(42), want: "LeadingTitle(42)"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.give.String())
+		})
+	}
+}
+
+func TestLeadingTitle_TextRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, give := range []LeadingTitle{LeadingTitleKeep, LeadingTitleOmit, LeadingTitleAsTitle} {
+		give := give
+		t.Run(give.String(), func(t *testing.T) {
+			t.Parallel()
+
+			text, err := give.MarshalText()
+			require.NoError(t, err)
+
+			var got LeadingTitle
+			require.NoError(t, got.UnmarshalText(text))
+			assert.Equal(t, give, got)
+		})
+	}
+}
+
+func TestLeadingTitle_UnmarshalTextError(t *testing.T) {
+	t.Parallel()
+
+	var l LeadingTitle
+	assert.ErrorContains(t, l.UnmarshalText([]byte("drop")), `unknown leading title option "drop"`)
+
+	_, err := LeadingTitle(42).MarshalText()
+	assert.ErrorContains(t, err, "unknown leading title option 42")
+}
+
+func TestTransformerAfterTitle_htmlTitle(t *testing.T) {
+	t.Parallel()
+
+	// Headings from HTML blocks are not part of the document,
+	// so the TOC can't be placed after them.
+	src := []byte(strings.Join([]string{
+		"Intro",
+		"",
+		`<h1 id="title">Title</h1>`,
+		"",
+		"## Foo",
+	}, "\n") + "\n")
+
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithAutoHeadingID(),
+	).Parse(text.NewReader(src))
+
+	(&Transformer{
+		HTMLHeadings: true,
+		Position:     PositionAfterTitle,
+		LeadingTitle: LeadingTitleAsTitle,
+	}).Transform(doc.(*ast.Document), text.NewReader(src), parser.NewContext())
+
+	heading, ok := doc.FirstChild().(*ast.Heading)
+	require.True(t, ok, "first child must be a heading, got %T", doc.FirstChild())
+	assert.Equal(t, "Title", string(nodeText(src, heading)))
+	assert.Equal(t, ast.KindList, heading.NextSibling().Kind())
+}
+
+func TestTransformerAnchor(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(strings.Join([]string{
+		"Intro",
+		"",
+		"> Quote",
+		"",
+		"# Foo",
+	}, "\n") + "\n")
+
+	tests := []struct {
+		desc     string
+		quote    bool // whether the anchor returns the block quote
+		position Position
+		want     []ast.NodeKind
+	}{
+		{
+			desc:  "anchor",
+			quote: true,
+			want: []ast.NodeKind{
+				ast.KindParagraph, ast.KindBlockquote,
+				ast.KindHeading, ast.KindList, // TOC
+				ast.KindHeading,
+			},
+		},
+		{
+			desc:     "nil anchor",
+			position: PositionBottom,
+			want: []ast.NodeKind{
+				ast.KindParagraph, ast.KindBlockquote, ast.KindHeading,
+				ast.KindHeading, ast.KindList, // TOC
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+			).Parse(text.NewReader(src)).(*ast.Document)
+
+			(&Transformer{
+				Position: tt.position,
+				Anchor: func(doc *ast.Document) ast.Node {
+					if tt.quote {
+						return firstChildOfKind(doc, ast.KindBlockquote)
+					}
+					return nil
+				},
+			}).Transform(doc, text.NewReader(src), parser.NewContext())
+
+			var got []ast.NodeKind
+			for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+				got = append(got, c.Kind())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTransformerWrap(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(strings.Join([]string{
+		"# Foo",
+		"## Bar",
+	}, "\n") + "\n")
+
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(
+			util.Prioritized(&Transformer{Wrap: true}, 100),
+		),
+	).Parse(text.NewReader(src))
+
+	node, ok := doc.FirstChild().(*Node)
+	require.True(t, ok, "first child must be a Node, got %T", doc.FirstChild())
+	assert.Equal(t, KindNode, node.Kind())
+	assert.Equal(t, 2, node.ChildCount(), "child count mismatch")
+	assert.IsType(t, (*ast.Heading)(nil), node.FirstChild())
+	assert.IsType(t, (*ast.List)(nil), node.LastChild())
+
+	require.NotNil(t, node.TOC)
+	assert.Equal(t, Items{
+		item("Foo", "foo",
+			item("Bar", "bar")),
+	}, node.TOC.Items)
+}
+
+func TestTransformerFilter(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(strings.Join([]string{
+		"# Foo",
+		"## Bar",
+		"## Baz",
+	}, "\n") + "\n")
+
+	doc := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(
+			util.Prioritized(&Transformer{
+				Wrap: true,
+				Filter: func(h *ast.Heading, src []byte) bool {
+					return string(nodeText(src, h)) != "Bar"
+				},
+			}, 100),
+		),
+	).Parse(text.NewReader(src))
+
+	node, ok := doc.FirstChild().(*Node)
+	require.True(t, ok, "first child must be a Node, got %T", doc.FirstChild())
+	assert.Equal(t, Items{
+		item("Foo", "foo",
+			item("Baz", "baz")),
+	}, node.TOC.Items)
+}
+
+func TestTransformerIgnore(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(strings.Join([]string{
+		"# Foo {.notoc}",
+		`# Bar {data-toc="false"}`,
+		"<!-- toc:ignore -->",
+		"# Baz",
+		"# Qux {.hidden}",
+	}, "\n") + "\n")
+
+	tests := []struct {
+		desc string
+		give Transformer
+		want Items
+	}{
+		{
+			desc: "default",
+			want: Items{item("Qux", "qux")},
+		},
+		{
+			desc: "custom",
+			give: Transformer{
+				IgnoreClass:     "hidden",
+				IgnoreAttribute: "toc",
+				IgnoreComment:   "skip",
+			},
+			want: Items{
+				item("Foo", "foo"),
+				item("Bar", "bar"),
+				item("Baz", "baz"),
+			},
+		},
+		{
+			desc: "disabled",
+			give: Transformer{
+				IgnoreClass:     "-",
+				IgnoreAttribute: "-",
+				IgnoreComment:   "-",
+			},
+			want: Items{
+				item("Foo", "foo"),
+				item("Bar", "bar"),
+				item("Baz", "baz"),
+				item("Qux", "qux"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			transformer := tt.give
+			transformer.Wrap = true
+			doc := parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+				parser.WithHeadingAttribute(),
+				parser.WithASTTransformers(
+					util.Prioritized(&transformer, 100),
+				),
+			).Parse(text.NewReader(src))
+
+			node, ok := doc.FirstChild().(*Node)
+			require.True(t, ok, "first child must be a Node, got %T", doc.FirstChild())
+			assert.Equal(t, tt.want, node.TOC.Items)
+		})
+	}
+}
+
+func TestTransformerConfigure(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(strings.Join([]string{
+		"# Foo",
+		"## Bar",
+	}, "\n") + "\n")
+
+	var calls int
+	transformer := &Transformer{
+		Title: "Contents",
+		Configure: func(_ parser.Context, t *Transformer) bool {
+			calls++
+			if calls > 1 {
+				return false
+			}
+			t.Title = "Overview"
+			return true
+		},
+	}
+	p := parser.NewParser(
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(util.Prioritized(transformer, 100)),
+	)
+
+	doc := p.Parse(text.NewReader(src))
+	heading, ok := doc.FirstChild().(*ast.Heading)
+	require.True(t, ok, "first child must be a heading, got %T", doc.FirstChild())
+	assert.Equal(t, "Overview", string(nodeText(src, heading)))
+	assert.Equal(t, "Contents", transformer.Title, "transformer must not be modified")
+
+	doc = p.Parse(text.NewReader(src))
+	heading, ok = doc.FirstChild().(*ast.Heading)
+	require.True(t, ok, "first child must be a heading, got %T", doc.FirstChild())
+	assert.Equal(t, "Foo", string(nodeText(src, heading)), "table of contents must be skipped")
+}
+
+func TestTransformerContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		give      string
+		minItems  int
+		configure func(parser.Context, *Transformer) bool
+		want      *TOC
+	}{
+		{
+			desc: "headings",
+			give: "# Foo\n## Bar\n",
+			want: &TOC{
+				Items: Items{
+					item("Foo", "foo",
+						item("Bar", "bar")),
+				},
+			},
+		},
+		{
+			desc: "no headings",
+			give: "Foo\n",
+			want: &TOC{},
+		},
+		{
+			desc: "disabled",
+			give: "# Foo\n",
+			configure: func(parser.Context, *Transformer) bool {
+				return false
+			},
+		},
+		{
+			desc:     "enough items",
+			give:     "# Foo\n## Bar\n",
+			minItems: 2,
+			want: &TOC{
+				Items: Items{
+					item("Foo", "foo",
+						item("Bar", "bar")),
+				},
+			},
+		},
+		{
+			desc:     "too few items",
+			give:     "# Foo\n## Bar\n",
+			minItems: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := parser.NewContext()
+			parser.NewParser(
+				parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+				parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+				parser.WithAutoHeadingID(),
+				parser.WithASTTransformers(
+					util.Prioritized(&Transformer{
+						MinItems:  tt.minItems,
+						Configure: tt.configure,
+					}, 100),
+				),
+			).Parse(text.NewReader([]byte(tt.give)), parser.WithContext(ctx))
+
+			assert.Equal(t, tt.want, FromContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package toc
+
+import (
+	"iter"
+	"slices"
+)
+
+// WalkStatus specifies how Walk should proceed after visiting an item.
+type WalkStatus int
+
+const (
+	// WalkContinue continues to the children of the item,
+	// and then to the rest of the tree.
+	WalkContinue WalkStatus = iota
+
+	// WalkSkipChildren skips the children of the item,
+	// and continues to the rest of the tree.
+	WalkSkipChildren
+
+	// WalkStop stops the walk.
+	WalkStop
+)
+
+// WalkFunc is called by Walk for each item in a table of contents.
+// depth is the depth of the item in the table of contents,
+// starting at 1 for top-level items.
+type WalkFunc func(item *Item, depth int) WalkStatus
+
+// FlatItem is an item in a flattened table of contents.
+// See Items.Flatten.
+type FlatItem struct {
+	// Item is the item in the table of contents.
+	Item *Item
+
+	// Depth is the depth of the item in the table of contents,
+	// starting at 1 for top-level items.
+	Depth int
+}
+
+// Walk visits items and their descendants in depth-first order,
+// calling fn for each item before its children.
+//
+// fn may return WalkSkipChildren to skip the children of an item,
+// or WalkStop to stop the walk.
+func (items Items) Walk(fn WalkFunc) {
+	items.walk(fn, 1)
+}
+
+// walk reports false if the walk was stopped.
+func (items Items) walk(fn WalkFunc, depth int) bool {
+	for _, item := range items {
+		switch fn(item, depth) {
+		case WalkStop:
+			return false
+		case WalkSkipChildren:
+			continue
+		}
+
+		if !item.Items.walk(fn, depth+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator over items and their descendants
+// in depth-first order: each item is followed by its children.
+//
+// For example, given the following:
+//
+//	Foo
+//	  Bar
+//	Baz
+//
+// All yields Foo, Bar, Baz.
+func (items Items) All() iter.Seq[*Item] {
+	return func(yield func(*Item) bool) {
+		items.Walk(func(item *Item, _ int) WalkStatus {
+			if !yield(item) {
+				return WalkStop
+			}
+			return WalkContinue
+		})
+	}
+}
+
+// BreadthFirst returns an iterator over items and their descendants
+// in breadth-first order: all items at a depth
+// are yielded before items at the next depth.
+//
+// For example, given the following:
+//
+//	Foo
+//	  Bar
+//	Baz
+//
+// BreadthFirst yields Foo, Baz, Bar.
+func (items Items) BreadthFirst() iter.Seq[*Item] {
+	return func(yield func(*Item) bool) {
+		queue := slices.Clone(items)
+		for len(queue) > 0 {
+			item := queue[0]
+			queue = queue[1:]
+			if !yield(item) {
+				return
+			}
+			queue = append(queue, item.Items...)
+		}
+	}
+}
+
+// Flatten returns items and their descendants in depth-first order,
+// alongside their depths in the table of contents.
+//
+// For example, given the following:
+//
+//	Foo
+//	  Bar
+//	Baz
+//
+// Flatten returns:
+//
+//	{Foo, 1}, {Bar, 2}, {Baz, 1}
+func (items Items) Flatten() []FlatItem {
+	var flat []FlatItem
+	items.Walk(func(item *Item, depth int) WalkStatus {
+		flat = append(flat, FlatItem{Item: item, Depth: depth})
+		return WalkContinue
+	})
+	return flat
+}
+
+// Find returns the first item with the given ID
+// in depth-first order, or nil if there isn't one.
+func (items Items) Find(id string) *Item {
+	if len(id) == 0 {
+		return nil
+	}
+
+	for item := range items.All() {
+		if string(item.ID) == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// Parent returns the item that the given item is nested under,
+// or nil if it's a top-level item or isn't in the tree.
+func (items Items) Parent(item *Item) *Item {
+	for parent := range items.All() {
+		if slices.Contains(parent.Items, item) {
+			return parent
+		}
+	}
+	return nil
+}
+
+// Next returns the item after the given item
+// in depth-first order (see All),
+// or nil if it's the last item or isn't in the tree.
+//
+// For example, given the following:
+//
+//	Foo
+//	  Bar
+//	Baz
+//
+// The item after Foo is Bar, and the item after Bar is Baz.
+func (items Items) Next(item *Item) *Item {
+	var found bool
+	for it := range items.All() {
+		if found {
+			return it
+		}
+		found = it == item
+	}
+	return nil
+}
+
+// Prev returns the item before the given item
+// in depth-first order (see All),
+// or nil if it's the first item or isn't in the tree.
+func (items Items) Prev(item *Item) *Item {
+	var prev *Item
+	for it := range items.All() {
+		if it == item {
+			return prev
+		}
+		prev = it
+	}
+	return nil
+}
+
+// Walk visits items in the table of contents in depth-first order.
+// See Items.Walk for details.
+func (t *TOC) Walk(fn WalkFunc) {
+	if t != nil {
+		t.Items.Walk(fn)
+	}
+}
+
+// All returns an iterator over items in the table of contents
+// in depth-first order.
+// See Items.All for details.
+func (t *TOC) All() iter.Seq[*Item] {
+	if t == nil {
+		return Items(nil).All()
+	}
+	return t.Items.All()
+}
+
+// BreadthFirst returns an iterator over items in the table of contents
+// in breadth-first order.
+// See Items.BreadthFirst for details.
+func (t *TOC) BreadthFirst() iter.Seq[*Item] {
+	if t == nil {
+		return Items(nil).BreadthFirst()
+	}
+	return t.Items.BreadthFirst()
+}
+
+// Flatten returns items in the table of contents
+// in depth-first order alongside their depths.
+// See Items.Flatten for details.
+func (t *TOC) Flatten() []FlatItem {
+	if t == nil {
+		return nil
+	}
+	return t.Items.Flatten()
+}
+
+// Find returns the first item in the table of contents
+// with the given ID, or nil if there isn't one.
+func (t *TOC) Find(id string) *Item {
+	if t == nil {
+		return nil
+	}
+	return t.Items.Find(id)
+}
+
+// Parent returns the item that the given item is nested under,
+// or nil if it's a top-level item or isn't in the table of contents.
+func (t *TOC) Parent(item *Item) *Item {
+	if t == nil {
+		return nil
+	}
+	return t.Items.Parent(item)
+}
+
+// Next returns the item after the given item
+// in depth-first order, or nil if there isn't one.
+// See Items.Next for details.
+func (t *TOC) Next(item *Item) *Item {
+	if t == nil {
+		return nil
+	}
+	return t.Items.Next(item)
+}
+
+// Prev returns the item before the given item
+// in depth-first order, or nil if there isn't one.
+// See Items.Prev for details.
+func (t *TOC) Prev(item *Item) *Item {
+	if t == nil {
+		return nil
+	}
+	return t.Items.Prev(item)
+}
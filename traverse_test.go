@@ -0,0 +1,241 @@
+package toc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testTree builds the following table of contents:
+//
+//	Foo
+//	  Bar
+//	    Baz
+//	  Qux
+//	Quux
+func testTree() *TOC {
+	return &TOC{
+		Items: Items{
+			item("Foo", "foo",
+				item("Bar", "bar",
+					item("Baz", "baz")),
+				item("Qux", "qux")),
+			item("Quux", "quux"),
+		},
+	}
+}
+
+func titles(items []*Item) []string {
+	var got []string
+	for _, it := range items {
+		got = append(got, string(it.Title))
+	}
+	return got
+}
+
+func TestTOCAll(t *testing.T) {
+	t.Parallel()
+
+	tree := testTree()
+	assert.Equal(t,
+		[]string{"Foo", "Bar", "Baz", "Qux", "Quux"},
+		titles(slices.Collect(tree.All())))
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		var got []*Item
+		for it := range testTree().All() {
+			got = append(got, it)
+			if string(it.Title) == "Baz" {
+				break
+			}
+		}
+		assert.Equal(t, []string{"Foo", "Bar", "Baz"}, titles(got))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		var tree *TOC
+		assert.Empty(t, slices.Collect(tree.All()))
+	})
+}
+
+func TestTOCBreadthFirst(t *testing.T) {
+	t.Parallel()
+
+	tree := testTree()
+	assert.Equal(t,
+		[]string{"Foo", "Quux", "Bar", "Qux", "Baz"},
+		titles(slices.Collect(tree.BreadthFirst())))
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		var got []*Item
+		for it := range testTree().BreadthFirst() {
+			got = append(got, it)
+			if string(it.Title) == "Bar" {
+				break
+			}
+		}
+		assert.Equal(t, []string{"Foo", "Quux", "Bar"}, titles(got))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		var tree *TOC
+		assert.Empty(t, slices.Collect(tree.BreadthFirst()))
+	})
+}
+
+func TestTOCWalk(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		fn   func(*Item) WalkStatus
+		want []string
+	}{
+		{
+			desc: "continue",
+			fn:   func(*Item) WalkStatus { return WalkContinue },
+			want: []string{"Foo", "Bar", "Baz", "Qux", "Quux"},
+		},
+		{
+			desc: "skip children",
+			fn: func(it *Item) WalkStatus {
+				if string(it.Title) == "Bar" {
+					return WalkSkipChildren
+				}
+				return WalkContinue
+			},
+			want: []string{"Foo", "Bar", "Qux", "Quux"},
+		},
+		{
+			desc: "stop",
+			fn: func(it *Item) WalkStatus {
+				if string(it.Title) == "Baz" {
+					return WalkStop
+				}
+				return WalkContinue
+			},
+			want: []string{"Foo", "Bar", "Baz"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var got []*Item
+			testTree().Walk(func(it *Item, _ int) WalkStatus {
+				got = append(got, it)
+				return tt.fn(it)
+			})
+			assert.Equal(t, tt.want, titles(got))
+		})
+	}
+}
+
+func TestTOCFlatten(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	var depths []int
+	for _, flat := range testTree().Flatten() {
+		got = append(got, string(flat.Item.Title))
+		depths = append(depths, flat.Depth)
+	}
+	assert.Equal(t, []string{"Foo", "Bar", "Baz", "Qux", "Quux"}, got)
+	assert.Equal(t, []int{1, 2, 3, 2, 1}, depths)
+
+	var tree *TOC
+	assert.Empty(t, tree.Flatten())
+}
+
+func TestTOCFind(t *testing.T) {
+	t.Parallel()
+
+	tree := testTree()
+	tree.Items = append(tree.Items, item("", "",
+		item("Baz again", "baz")))
+
+	tests := []struct {
+		give string
+		want string // title of the item, or empty if not found
+	}{
+		{give: "foo", want: "Foo"},
+		{give: "baz", want: "Baz"},
+		{give: "quux", want: "Quux"},
+		{give: "missing"},
+		{give: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			got := tree.Find(tt.give)
+			if tt.want == "" {
+				assert.Nil(t, got)
+			} else if assert.NotNil(t, got) {
+				assert.Equal(t, tt.want, string(got.Title))
+			}
+		})
+	}
+}
+
+func TestTOCNeighbors(t *testing.T) {
+	t.Parallel()
+
+	tree := testTree()
+	foo := tree.Find("foo")
+	bar := tree.Find("bar")
+	baz := tree.Find("baz")
+	qux := tree.Find("qux")
+	quux := tree.Find("quux")
+	missing := item("Missing", "missing")
+
+	tests := []struct {
+		desc string
+		give *Item
+
+		wantParent *Item
+		wantNext   *Item
+		wantPrev   *Item
+	}{
+		{desc: "first", give: foo, wantNext: bar},
+		{desc: "nested", give: bar, wantParent: foo, wantNext: baz, wantPrev: foo},
+		{desc: "deepest", give: baz, wantParent: bar, wantNext: qux, wantPrev: bar},
+		{desc: "after nested", give: qux, wantParent: foo, wantNext: quux, wantPrev: baz},
+		{desc: "last", give: quux, wantPrev: qux},
+		{desc: "missing", give: missing},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Same(t, tt.wantParent, tree.Parent(tt.give), "parent")
+			assert.Same(t, tt.wantNext, tree.Next(tt.give), "next")
+			assert.Same(t, tt.wantPrev, tree.Prev(tt.give), "prev")
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		var tree *TOC
+		assert.Nil(t, tree.Parent(foo))
+		assert.Nil(t, tree.Next(foo))
+		assert.Nil(t, tree.Prev(foo))
+		assert.Nil(t, tree.Find("foo"))
+	})
+}